@@ -0,0 +1,219 @@
+package fluent
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/lib/pq"
+)
+
+// copyBatchSize is the number of records copied per COPY statement
+const copyBatchSize = 10000
+
+// CopyIterator returns the next record to copy,
+// it returns false when there are no more records
+type CopyIterator func() (interface{}, bool)
+
+// CopyResult holds the number of copied rows
+// and the errors of the failed batches
+type CopyResult struct {
+	Rows   int64
+	Errors []*CopyBatchError
+}
+
+// CopyBatchError is the error of a batch that failed to copy
+type CopyBatchError struct {
+	// Batch is the zero based number of the batch
+	Batch int
+	// Rows is the number of records in the batch
+	Rows int
+	Err  error
+}
+
+func (e *CopyBatchError) Error() string {
+	return fmt.Sprintf("Copy of batch %d with %d rows failed: %s", e.Batch, e.Rows, e.Err)
+}
+
+// Unwrap returns the error of the batch
+func (e *CopyBatchError) Unwrap() error {
+	return e.Err
+}
+
+// CopyFrom bulk loads the records from the source into the table with COPY FROM.
+// The source can be a slice or a channel of structs or a CopyIterator, the values
+// are taken from the fields tagged with the given columns. When no columns are
+// provided the columns Insert would write for the first record are used, the
+// fields that are set and the keepzero fields, so the columns that are zero
+// keep their database defaults. Provide the columns when the records don't
+// all set the same fields.
+//
+// The records are copied in batches, outside a transaction every batch is copied
+// in its own transaction and a failed batch doesn't stop the next batches. The
+// returned error is the error of the first failed batch. Within a transaction
+// the copy stops at the first failed batch.
+//
+// The copy stops when the context is done, the batches that were copied outside
+// a transaction are kept. When the copy stops early the rest of a channel is
+// received until it's closed or the context is done, so the sender isn't blocked.
+func (f *Fluent) CopyFrom(ctx context.Context, table string, columns []string, source interface{}) (CopyResult, error) {
+	var result CopyResult
+
+	next, err := copySource(ctx, source)
+	if err != nil {
+		return result, err
+	}
+	if reflect.Indirect(reflect.ValueOf(source)).Kind() == reflect.Chan {
+		defer drain(next)
+	}
+
+	for batch := 0; ; batch++ {
+		records := readBatch(next, copyBatchSize)
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if len(records) == 0 {
+			break
+		}
+
+		if len(columns) == 0 {
+			if columns, err = copyColumns(records[0]); err != nil {
+				return result, err
+			}
+		}
+
+		if err := f.copyBatch(ctx, table, columns, records); err != nil {
+			batchErr := &CopyBatchError{batch, len(records), err}
+			result.Errors = append(result.Errors, batchErr)
+			if f.tx != nil {
+				break
+			}
+			continue
+		}
+
+		result.Rows += int64(len(records))
+	}
+
+	if len(result.Errors) > 0 {
+		return result, result.Errors[0]
+	}
+	return result, nil
+}
+
+// copyBatch copies the records with a single COPY statement
+func (f *Fluent) copyBatch(ctx context.Context, table string, columns []string, records []interface{}) error {
+	run := func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, pq.CopyIn(table, columns...))
+		if err != nil {
+			return wrapError(err)
+		}
+		defer stmt.Close()
+
+		for _, record := range records {
+			values, err := getColumnValues(record, columns)
+			if err != nil {
+				return err
+			}
+
+			if _, err := stmt.ExecContext(ctx, values...); err != nil {
+				return wrapError(err)
+			}
+		}
+
+		// Flush the buffered rows
+		_, err = stmt.ExecContext(ctx)
+		return wrapError(err)
+	}
+
+	if f.tx != nil {
+		return run(f.tx)
+	}
+
+	tx, err := f.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := run(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return wrapError(tx.Commit())
+}
+
+// copyColumns returns the columns getStructValues writes for the record,
+// the tagged fields that are set and the fields with the keepzero option
+func copyColumns(record interface{}) ([]string, error) {
+	columns, _, err := getStructValues(record)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("No columns to copy, the first record has no fields set")
+	}
+	return columns, nil
+}
+
+// copySource returns an iterator over the records of the source,
+// receiving from a channel stops when the context is done
+func copySource(ctx context.Context, source interface{}) (CopyIterator, error) {
+	switch it := source.(type) {
+	case CopyIterator:
+		return it, nil
+	case func() (interface{}, bool):
+		return it, nil
+	}
+
+	valOf := reflect.Indirect(reflect.ValueOf(source))
+	switch valOf.Kind() {
+	case reflect.Slice:
+		i := 0
+		return func() (interface{}, bool) {
+			if i >= valOf.Len() {
+				return nil, false
+			}
+			i++
+			return valOf.Index(i - 1).Interface(), true
+		}, nil
+	case reflect.Chan:
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: valOf},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+		}
+		return func() (interface{}, bool) {
+			chosen, v, ok := reflect.Select(cases)
+			if chosen > 0 || !ok {
+				return nil, false
+			}
+			return v.Interface(), true
+		}, nil
+	}
+
+	return nil, fmt.Errorf("The provided source is not a slice, channel or iterator")
+}
+
+// drain receives the rest of a channel until it's closed or the context is done
+func drain(next CopyIterator) {
+	for {
+		if _, ok := next(); !ok {
+			return
+		}
+	}
+}
+
+// readBatch reads up to size records from the iterator
+func readBatch(next CopyIterator, size int) []interface{} {
+	var records []interface{}
+	for len(records) < size {
+		record, ok := next()
+		if !ok {
+			break
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
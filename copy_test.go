@@ -0,0 +1,154 @@
+package fluent
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CopySource(t *testing.T) {
+	require := require.New(t)
+
+	records := []scanTest{{ID: 1}, {ID: 2}, {ID: 3}}
+
+	recordChan := make(chan scanTest, len(records))
+	for _, record := range records {
+		recordChan <- record
+	}
+	close(recordChan)
+
+	i := 0
+	iterator := func() (interface{}, bool) {
+		if i >= len(records) {
+			return nil, false
+		}
+		i++
+		return records[i-1], true
+	}
+
+	tests := []struct {
+		source      interface{}
+		expectedErr bool
+	}{
+		{
+			source: records,
+		},
+		{
+			source: &records,
+		},
+		{
+			source: recordChan,
+		},
+		{
+			source: iterator,
+		},
+		{
+			source:      records[0],
+			expectedErr: true,
+		},
+		{
+			source:      nil,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		next, err := copySource(context.Background(), tc.source)
+		if tc.expectedErr {
+			require.NotNil(err)
+			continue
+		}
+		require.Nil(err)
+
+		batch := readBatch(next, 2)
+		require.Equal([]interface{}{records[0], records[1]}, batch)
+
+		batch = readBatch(next, 2)
+		require.Equal([]interface{}{records[2]}, batch)
+
+		batch = readBatch(next, 2)
+		require.Len(batch, 0)
+	}
+}
+
+func Test_CopyFromChannel(t *testing.T) {
+	require := require.New(t)
+
+	// The channel is received until it's closed when the copy stops early
+	records := make(chan scanTest)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < copyBatchSize+5; i++ {
+			records <- scanTest{}
+		}
+		close(records)
+	}()
+
+	_, err := New(nil).CopyFrom(context.Background(), "test", nil, records)
+	require.NotNil(err)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("The sender is blocked")
+	}
+
+	// A channel that is never closed stops with the context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err = New(nil).CopyFrom(ctx, "test", []string{"name"}, make(chan scanTest))
+	require.Equal(context.Canceled, err)
+}
+
+func Test_CopyColumns(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		record      interface{}
+		expected    []string
+		expectedErr bool
+	}{
+		{
+			record:   scanTest{Name: "gerald"},
+			expected: []string{"name"},
+		},
+		{
+			record:   &scanTest{ID: 1, Total: 12.00},
+			expected: []string{"id", "total"},
+		},
+		{
+			record:   tagTest{},
+			expected: []string{"name", "is_active"},
+		},
+		{
+			record:   pkTest{Name: "gerald"},
+			expected: []string{"name"},
+		},
+		{
+			record:   pkTest{UUID: "a0eebc99", Name: "gerald"},
+			expected: []string{"uuid", "name"},
+		},
+		{
+			record:      scanTest{},
+			expectedErr: true,
+		},
+		{
+			record:      1,
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		columns, err := copyColumns(tc.record)
+		if tc.expectedErr {
+			require.NotNil(err)
+			continue
+		}
+		require.Nil(err)
+		require.Equal(tc.expected, columns)
+	}
+}
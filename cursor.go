@@ -0,0 +1,132 @@
+package fluent
+
+import "database/sql"
+
+// Cursor iterates over the rows of a query without buffering them,
+// it has to be closed when done
+type Cursor struct {
+	stmt    *statement
+	rows    *sql.Rows
+	columns []string
+	row     []interface{}
+	values  map[string]interface{}
+	strict  bool
+	err     error
+}
+
+// Cursor executes the query and returns a cursor over the rows
+func (f *Fluent) Cursor() (*Cursor, error) {
+	if f.query.err != nil {
+		return nil, f.query.err
+	}
+	defer f.query.log()
+
+	var rows *sql.Rows
+	stmt, err := f.statement(func(stmt *sql.Stmt) (err error) {
+		rows, err = stmt.QueryContext(f.ctx, f.query.args...)
+		return err
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		rows.Close()
+		stmt.Close()
+		return nil, err
+	}
+
+	// The values are decoded with the database type of the columns
+	columns := make([]string, len(types))
+	row := make([]interface{}, len(types))
+	for i, columnType := range types {
+		columns[i] = columnType.Name()
+		row[i] = &scanner{typeName: columnType.DatabaseTypeName()}
+	}
+
+	return &Cursor{
+		stmt:    stmt,
+		rows:    rows,
+		columns: columns,
+		row:     row,
+		values:  make(map[string]interface{}, len(columns)),
+		strict:  f.query.strict,
+	}, nil
+}
+
+// Each scans every row into s, which is a pointer to a struct or a map,
+// and calls fn after each row. Iterating stops when fn returns an error,
+// the error is returned unless it's ErrStop
+func (f *Fluent) Each(s interface{}, fn func() error) error {
+	cursor, err := f.Cursor()
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	for cursor.Next() {
+		if err := cursor.Scan(s); err != nil {
+			return err
+		}
+
+		if err := fn(); err != nil {
+			if err == ErrStop {
+				return nil
+			}
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// Next prepares the next row, it returns false when there are
+// no more rows or an error occurred, check Err to tell them apart
+func (c *Cursor) Next() bool {
+	if c.err != nil || !c.rows.Next() {
+		return false
+	}
+
+	if err := c.rows.Scan(c.row...); err != nil {
+		c.err = err
+		return false
+	}
+
+	for i, column := range c.columns {
+		c.values[column] = c.row[i].(*scanner).value
+	}
+
+	return true
+}
+
+// Scan the current row into s, a pointer to a struct or a map
+func (c *Cursor) Scan(s interface{}) error {
+	return c.scan(s, &one{c.strict})
+}
+
+func (c *Cursor) scan(s interface{}, st scannerType) error {
+	return st.scan(s, c.columns, c.values)
+}
+
+// Columns returns the column names of the rows
+func (c *Cursor) Columns() []string {
+	return c.columns
+}
+
+// Err returns the error that stopped the iteration
+func (c *Cursor) Err() error {
+	if c.err != nil {
+		return c.err
+	}
+	return wrapError(c.rows.Err())
+}
+
+// Close the rows and the statement
+func (c *Cursor) Close() error {
+	err := c.rows.Close()
+	if stmtErr := c.stmt.Close(); err == nil {
+		err = stmtErr
+	}
+	return err
+}
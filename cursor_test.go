@@ -0,0 +1,119 @@
+package fluent
+
+import (
+	"database/sql"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+type cursorTest struct {
+	ID int `sql:"id"`
+}
+
+func Test_Each(t *testing.T) {
+	require := require.New(t)
+
+	errCallback := errors.New("callback failed")
+	tests := []struct {
+		name        string
+		rowsErr     error
+		dest        interface{}
+		fn          func(ids []int) error
+		expectedIDs []int
+		expectedErr error
+	}{
+		{
+			name:        "All the rows",
+			dest:        &cursorTest{},
+			fn:          func(ids []int) error { return nil },
+			expectedIDs: []int{1, 2, 3},
+		},
+		{
+			name: "ErrStop ends without an error",
+			dest: &cursorTest{},
+			fn: func(ids []int) error {
+				if len(ids) == 2 {
+					return ErrStop
+				}
+				return nil
+			},
+			expectedIDs: []int{1, 2},
+		},
+		{
+			name:        "The error of the function is returned",
+			dest:        &cursorTest{},
+			fn:          func(ids []int) error { return errCallback },
+			expectedIDs: []int{1},
+			expectedErr: errCallback,
+		},
+		{
+			name:        "The scan error is returned",
+			dest:        new(int),
+			fn:          func(ids []int) error { return nil },
+			expectedErr: errors.New("The provided interface is not a struct"),
+		},
+		{
+			name:        "The error of the rows is returned",
+			rowsErr:     &pq.Error{Code: "57014", Message: "canceling statement"},
+			dest:        &cursorTest{},
+			fn:          func(ids []int) error { return nil },
+			expectedIDs: []int{1, 2, 3},
+			expectedErr: ErrQueryCanceled,
+		},
+	}
+
+	for _, tc := range tests {
+		d := &stmtDriver{rows: 3, rowsErr: tc.rowsErr}
+		db := sql.OpenDB(d)
+
+		var ids []int
+		err := New(db).Table("test_1").Get("id").Each(tc.dest, func() error {
+			ids = append(ids, tc.dest.(*cursorTest).ID)
+			return tc.fn(ids)
+		})
+
+		switch tc.expectedErr {
+		case nil:
+			require.Nil(err, tc.name)
+		case errCallback, ErrQueryCanceled:
+			require.True(errors.Is(err, tc.expectedErr), tc.name)
+		default:
+			require.EqualError(err, tc.expectedErr.Error(), tc.name)
+		}
+		require.Equal(tc.expectedIDs, ids, tc.name)
+
+		// The rows and the statement are closed however the iteration ended
+		require.Equal(int32(1), atomic.LoadInt32(&d.rowsClosed), tc.name)
+		require.Equal(int32(1), atomic.LoadInt32(&d.prepared), tc.name)
+		require.Equal(int32(1), atomic.LoadInt32(&d.closed), tc.name)
+		db.Close()
+	}
+}
+
+func Test_CursorErr(t *testing.T) {
+	require := require.New(t)
+
+	d := &stmtDriver{rows: 1, rowsErr: errors.New("connection reset")}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	cursor, err := New(db).Table("test_1").Get("id").Cursor()
+	require.Nil(err)
+	require.Equal([]string{"id"}, cursor.Columns())
+
+	record := cursorTest{}
+	require.True(cursor.Next())
+	require.Nil(cursor.Scan(&record))
+	require.Equal(1, record.ID)
+
+	require.False(cursor.Next())
+	require.EqualError(cursor.Err(), "connection reset")
+
+	require.Nil(cursor.Close())
+	require.Equal(int32(1), atomic.LoadInt32(&d.rowsClosed))
+	require.Equal(int32(1), atomic.LoadInt32(&d.closed))
+}
@@ -34,10 +34,101 @@ The Fluent ORM uses the tags in the struct to find and set values.
 	    Total string `sql:"total"`
 	}
 
+Zero values are skipped when inserting or updating a record, use
+the keepzero option to always write the value of the field.
+	type Record struct {
+	    ID       int  `sql:"id"`
+	    IsActive bool `sql:"is_active,keepzero"`
+	}
+
+Fields implementing sql.Scanner or driver.Valuer, like sql.NullString
+or a decimal type, scan and write their own values.
+	type Record struct {
+	    Name  sql.NullString  `sql:"name"`
+	    Total decimal.Decimal `sql:"total"`
+	}
+
+A NULL value sets a pointer field to nil and a sql.Null* field to invalid,
+other fields are set to their zero value. Use Strict to return
+ErrNullValue instead.
+	type Record struct {
+	    Name      *string    `sql:"name"`
+	    DeletedAt *time.Time `sql:"deleted_at"`
+	}
+
+	fluent := fluent.New(db).Strict(true)
+
+Values are decoded with the database type of the column, text stays text
+and a numeric value is an exact decimal string. Scan it into a float64,
+a string or a big.Rat field, bytea and json values are []byte. A big.Rat
+is written as a decimal string rounded to 32 decimals.
+	type Record struct {
+	    Total big.Rat `sql:"total"`
+	}
+
+Use the json option to marshal a field into a json or jsonb column,
+the column is unmarshalled into the field when scanning.
+	type Record struct {
+	    Settings Settings `sql:"settings,json"`
+	}
+
+Embedded structs are flattened, use the prefix option to map
+a nested struct to the prefixed columns, like the joined author_id
+and author_name columns. The prefixed columns belong to the joined
+table, they are only scanned and aren't written by Insert or Update.
+The same goes for the fields of a tagged pointer to a struct.
+	type Timestamps struct {
+	    CreatedAt time.Time  `sql:"created_at"`
+	    DeletedAt *time.Time `sql:"deleted_at"`
+	}
+
+	type Post struct {
+	    Timestamps
+	    ID     int    `sql:"id"`
+	    Author Author `sql:"author,prefix=author_"`
+	}
+
+Use the array option to write and scan a slice as a Postgres array.
+	type Record struct {
+	    Tags []string `sql:"tags,array"`
+	}
+
 Create Record
   record := Record{Name: "user_1", Total: 12.00}
   id, err := fluent.Table("test").Insert(record)
 
+Create Records
+  records := []Record{{Name: "user_1", Total: 12.00}, {Name: "user_2"}}
+  ids, err := fluent.Table("test").InsertMany(records)
+
+Bulk Load Records
+  // The source can be a slice, a channel or a fluent.CopyIterator
+  result, err := fluent.CopyFrom(ctx, "test", []string{"name", "total"}, records)
+  log.Println(result.Rows, result.Errors)
+
+Update Columns
+  // Update exactly the given columns including zero values
+  err := fluent.Table("test").Where("id", "=", 1).UpdateColumns(record, "name", "total")
+
+Upsert Record
+  // Update the name and total when the id already exists
+  id, inserted, err := fluent.Table("test").Upsert(record, "id")
+
+  id, inserted, err = fluent.Table("test").OnConstraint("test_name_key").DoUpdate("total").Upsert(record)
+  id, inserted, err = fluent.Table("test").DoNothing().Upsert(record, "name")
+
+The primary key returned by Insert is the id column, use the pk option
+to return a different column. Insert only returns integer keys, use
+InsertReturning for text or uuid keys.
+	type Code struct {
+	    Code      string    `sql:"code,pk"`
+	    CreatedAt time.Time `sql:"created_at"`
+	}
+
+  // Scan the generated columns back into the struct
+  code := Code{}
+  err := fluent.Table("codes").InsertReturning(code, &code)
+
 Update Record
   record := Record{Name: "user_2"}
   err := fluent.Table("test").Where("id","=", 1).Update(record)
@@ -51,6 +142,151 @@ Fetch Record
   records := []Record{}
   err = fluent.Table("test").Get("id","name", "total").All(&records)
 
+Typed Queries
+  // The columns are taken from the sql tags of Record
+  records, err := fluent.Query[Record](fluent, "test").Where("total", ">", 10).All(ctx)
+  record, err := fluent.Query[Record](fluent, "test").Where("id", "=", 1).First(ctx)
+
+Iterate Records
+  // The rows are streamed instead of buffered, return fluent.ErrStop to stop early
+  record := Record{}
+  err := fluent.Table("test").Get("*").Each(&record, func() error {
+    …
+    return nil
+  })
+
+  cursor, err := fluent.Table("test").Get("*").Cursor()
+  defer cursor.Close()
+
+  for cursor.Next() {
+    err := cursor.Scan(&record)
+    …
+  }
+  err = cursor.Err()
+
+Fetch Columns
+  names := []string{}
+  err := fluent.Table("test").Pluck("name", &names)
+
+  totals := map[int]float64{}
+  err = fluent.Table("test").PluckMap("id", "total", totals)
+
+  rows := []map[string]interface{}{}
+  err = fluent.Table("test").Get("id", "name").All(&rows)
+
+Aggregate Records
+  count, err := fluent.Table("test").WhereNull("deleted_at", true).Count()
+  total, err := fluent.Table("test").Where("name", "=", "user_1").Sum("total")
+
+  // Min and Max scan into a number, a string or a time.Time
+  var first time.Time
+  err = fluent.Table("test").Min("created_at", &first)
+
+  // A grouped count returns the number of groups
+  groups, err := fluent.Table("test").GroupBy("name").Count()
+  exists, err := fluent.Table("test").Where("id", "=", 1).Exists()
+
+Where Clauses
+  err := fluent.Table("test").
+    WhereIn("id", []int{1, 2, 3}).
+    WhereBetween("total", 10, 20).
+    WhereILike("name", "user_%").
+    Get("*").
+    All(&records)
+
+JSON Where Clauses
+  // SELECT * FROM test WHERE settings ->> $1 = $2 AND settings @> $3 AND settings ? $4
+  err := fluent.Table("test").
+    WhereJSONText("settings", "theme", "=", "dark").
+    WhereJSONContains("settings", map[string]bool{"beta": true}).
+    WhereJSONHasKey("settings", "tags").
+    Get("*").
+    All(&records)
+
+Array Where Clauses
+  // SELECT * FROM test WHERE $1 = ANY(tags) AND tags @> $2 AND tags && $3
+  err := fluent.Table("test").
+    WhereAny("tags", "go").
+    WhereContains("tags", []string{"go", "sql"}).
+    WhereOverlaps("tags", []string{"api", "cli"}).
+    Get("*").
+    All(&records)
+
+Grouped Where Clauses
+  // SELECT * FROM test WHERE (name = $1 OR total > $2) AND deleted_at IS NULL
+  err := fluent.Table("test").
+    WhereGroup(func(q fluent.QueryMapper) {
+      q.Where("name", "=", "user_1").OrWhere("total", ">", 10)
+    }).
+    WhereNull("deleted_at", true).
+    Get("*").
+    All(&records)
+
+Delete Record
+  err := fluent.Table("test").Where("id", "=", 1).Delete()
+
+  records := []Record{}
+  err = fluent.Table("test").WhereNull("name", true).DeleteReturning("id", "total").All(&records)
+
+  // Deleting without a where clause has to be confirmed
+  err = fluent.Table("test").Unfiltered().Delete()
+
+Context
+  ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+  defer cancel()
+
+  err := fluent.Table("test").WithContext(ctx).Where("id", "=", 1).Get("*").One(&record)
+
+Statement Cache
+  // Reuse up to 100 prepared statements instead of preparing every query
+  fluent := fluent.New(db).StmtCache(100)
+  …
+  stats := fluent.StmtCacheStats()
+  log.Println(stats.Hits, stats.Misses)
+
+Transactions
+  err := fluent.Transaction(ctx, func(tx fluent.Mapper) error {
+    if _, err := tx.Table("test").Insert(record); err != nil {
+      return err
+    }
+    return tx.Table("test").Where("id", "=", 1).Delete()
+  })
+
+  // A nested transaction uses a savepoint and only rolls back its own work
+  err = fluent.Transaction(ctx, func(tx fluent.Mapper) error {
+    …
+    return tx.Transaction(ctx, func(tx fluent.Mapper) error {
+      …
+    })
+  })
+
+  // Retry the transaction on serialization failures and deadlocks
+  policy := fluent.RetryPolicy{
+    MaxAttempts: 5,
+    Backoff:     fluent.ExponentialBackoff(10*time.Millisecond, time.Second),
+    TxOptions:   &sql.TxOptions{Isolation: sql.LevelSerializable},
+  }
+  err = fluent.RetryTransaction(ctx, policy, func(tx fluent.Mapper) error {
+    …
+  })
+
+  tx, err := fluent.Begin()
+  …
+  err = tx.Commit()
+
+Errors
+  err := fluent.Table("test").Where("id", "=", 1).Get("*").One(&record)
+  if errors.Is(err, fluent.ErrNoRows) {
+    …
+  }
+
+  _, err = fluent.Table("test").Insert(record)
+  if errors.Is(err, fluent.ErrUniqueViolation) {
+    var e *fluent.Error
+    errors.As(err, &e)
+    log.Println(e.Constraint)
+  }
+
 Join Records
   record := Record{}
   err := fluent.Table("test_1 as t2").Join("test_2 as t2", "t2.user_id", "t1.id").Get("t1.name").One(&record)
@@ -0,0 +1,129 @@
+package fluent
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	notNullViolation     pq.ErrorCode = "23502"
+	foreignKeyViolation  pq.ErrorCode = "23503"
+	uniqueViolation      pq.ErrorCode = "23505"
+	checkViolation       pq.ErrorCode = "23514"
+	serializationFailure pq.ErrorCode = "40001"
+	deadlockDetected     pq.ErrorCode = "40P01"
+	queryCanceled        pq.ErrorCode = "57014"
+)
+
+var (
+	// ErrNoRows is returned by One when the query didn't return any rows,
+	// it is the same error as sql.ErrNoRows
+	ErrNoRows = sql.ErrNoRows
+	// ErrStop can be returned by the function passed to Each
+	// to stop iterating over the rows without an error
+	ErrStop = errors.New("Stop iterating")
+	// ErrUnfilteredDelete is returned when a delete is executed
+	// without any where clause and Unfiltered wasn't called
+	ErrUnfilteredDelete = errors.New("Refusing to delete without a where clause, call Unfiltered to delete all the records")
+	// ErrTxStarted is returned when a transaction is started on a mapper
+	// that is already in a transaction, use Transaction to nest them
+	ErrTxStarted = errors.New("A transaction is already in progress")
+	// ErrNoTx is returned when committing or rolling back
+	// a mapper that isn't in a transaction
+	ErrNoTx = errors.New("No transaction in progress")
+	// ErrGroupedAggregate is returned by Sum, Avg, Min and Max when GroupBy
+	// is set, Count returns the number of groups of a grouped query
+	ErrGroupedAggregate = errors.New("Only Count and Exists can be used on a grouped query")
+	// ErrPrimaryKeyType is returned by Insert, InsertMany and Upsert when the
+	// primary key field isn't an integer, use InsertReturning to scan it
+	ErrPrimaryKeyType = errors.New("The primary key isn't an integer, use InsertReturning to scan it")
+	// ErrNoColumns is returned by Update and UpdateColumns
+	// when there are no columns to update
+	ErrNoColumns = errors.New("No columns to update")
+	// ErrNoConflictTarget is returned by Upsert when the record is
+	// updated on a conflict without a conflict target or constraint
+	ErrNoConflictTarget = errors.New("An upsert that updates on a conflict needs a conflict target or a constraint")
+	// ErrNullValue is returned in strict mode when a NULL value
+	// is scanned into a struct field that can't be NULL
+	ErrNullValue = errors.New("Can't scan a NULL value into the field")
+
+	// ErrUniqueViolation is the kind of the error returned
+	// when a unique constraint is violated
+	ErrUniqueViolation = errors.New("Unique violation")
+	// ErrForeignKeyViolation is the kind of the error returned
+	// when a foreign key constraint is violated
+	ErrForeignKeyViolation = errors.New("Foreign key violation")
+	// ErrNotNullViolation is the kind of the error returned
+	// when a not null constraint is violated
+	ErrNotNullViolation = errors.New("Not null violation")
+	// ErrCheckViolation is the kind of the error returned
+	// when a check constraint is violated
+	ErrCheckViolation = errors.New("Check violation")
+	// ErrQueryCanceled is the kind of the error returned
+	// when the query was canceled by the database
+	ErrQueryCanceled = errors.New("Query canceled")
+)
+
+// errorKinds maps the Postgres error codes to the error kinds
+var errorKinds = map[pq.ErrorCode]error{
+	uniqueViolation:     ErrUniqueViolation,
+	foreignKeyViolation: ErrForeignKeyViolation,
+	notNullViolation:    ErrNotNullViolation,
+	checkViolation:      ErrCheckViolation,
+	queryCanceled:       ErrQueryCanceled,
+}
+
+// Error is returned for classified Postgres errors, use errors.Is with
+// the error kind to check the type of error, for example:
+//
+//	if errors.Is(err, fluent.ErrUniqueViolation) {
+//		var e *fluent.Error
+//		errors.As(err, &e)
+//		log.Println(e.Constraint)
+//	}
+type Error struct {
+	Kind       error
+	Table      string
+	Column     string
+	Constraint string
+	Err        *pq.Error
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Message
+}
+
+// Is reports if the target is the kind of the error
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// Unwrap returns the underlying *pq.Error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrapError classifies the Postgres errors,
+// any other error is returned as is
+func wrapError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	kind, ok := errorKinds[pqErr.Code]
+	if !ok {
+		return err
+	}
+
+	return &Error{
+		Kind:       kind,
+		Table:      pqErr.Table,
+		Column:     pqErr.Column,
+		Constraint: pqErr.Constraint,
+		Err:        pqErr,
+	}
+}
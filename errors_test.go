@@ -0,0 +1,72 @@
+package fluent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WrapError(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		err          error
+		expectedKind error
+		constraint   string
+	}{
+		{
+			err:          &pq.Error{Code: "23505", Constraint: "test_1_name_key"},
+			expectedKind: ErrUniqueViolation,
+			constraint:   "test_1_name_key",
+		},
+		{
+			err:          &pq.Error{Code: "23503", Constraint: "test_2_test_id_fkey"},
+			expectedKind: ErrForeignKeyViolation,
+			constraint:   "test_2_test_id_fkey",
+		},
+		{
+			err:          &pq.Error{Code: "23502"},
+			expectedKind: ErrNotNullViolation,
+		},
+		{
+			err:          fmt.Errorf("Insert failed: %w", &pq.Error{Code: "23514", Constraint: "total_check"}),
+			expectedKind: ErrCheckViolation,
+			constraint:   "total_check",
+		},
+		{
+			err:          &pq.Error{Code: "57014"},
+			expectedKind: ErrQueryCanceled,
+		},
+		{
+			err: &pq.Error{Code: "42P01"},
+		},
+		{
+			err: errors.New("failed"),
+		},
+		{
+			err: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		err := wrapError(tc.err)
+
+		if tc.expectedKind == nil {
+			require.Equal(tc.err, err)
+			continue
+		}
+
+		require.True(errors.Is(err, tc.expectedKind))
+		require.False(errors.Is(err, ErrNoRows))
+
+		var e *Error
+		require.True(errors.As(err, &e))
+		require.Equal(tc.constraint, e.Constraint)
+
+		var pqErr *pq.Error
+		require.True(errors.As(err, &pqErr))
+	}
+}
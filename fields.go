@@ -0,0 +1,275 @@
+package fluent
+
+import (
+	"reflect"
+	"sync"
+)
+
+// fieldCache holds the *structFields of every struct type that has been
+// scanned or written, the fields are computed once per type
+var fieldCache sync.Map
+
+// field is a tagged struct field and the column it's mapped to
+type field struct {
+	name    string
+	column  string
+	index   []int
+	typ     reflect.Type
+	options tagOptions
+	// keepZero writes the value even when it's zero
+	keepZero bool
+	// nullable fields can be set to NULL
+	nullable bool
+	// readOnly fields of a prefixed struct or a tagged pointer to a
+	// struct are only scanned, the struct isn't part of the record
+	readOnly bool
+	// joined fields of a prefixed struct are the columns
+	// of a joined table, they aren't selected by default
+	joined bool
+	// convert sets a scanned value on the field
+	convert converter
+	// value returns the value of the field to write
+	value func(field reflect.Value) (interface{}, error)
+}
+
+// structFields are the tagged fields of a struct type, including the fields
+// of embedded structs, tagged pointers to a struct and prefixed structs
+type structFields struct {
+	fields     []*field
+	columns    []string
+	byColumn   map[string]*field
+	primaryKey string
+	// pointers are the nested pointers to a struct, the
+	// innermost pointers are listed before the outer ones
+	pointers []*nestedPointer
+	// path counts the struct types that are being added,
+	// it's only used while the fields are computed
+	path map[reflect.Type]int
+}
+
+// nestedPointer is a pointer to a struct and the columns of its fields,
+// it's left nil when all the scanned columns are NULL
+type nestedPointer struct {
+	index   []int
+	columns []string
+}
+
+// getStructFields returns the cached fields of the struct type
+func getStructFields(typeOf reflect.Type) *structFields {
+	if sf, ok := fieldCache.Load(typeOf); ok {
+		return sf.(*structFields)
+	}
+
+	sf, _ := fieldCache.LoadOrStore(typeOf, newStructFields(typeOf))
+	return sf.(*structFields)
+}
+
+func newStructFields(typeOf reflect.Type) *structFields {
+	sf := &structFields{byColumn: map[string]*field{}, path: map[reflect.Type]int{}}
+	sf.add(typeOf, nil, "", false)
+	sf.path = nil
+
+	// A column can only be mapped once, the field closest
+	// to the top level struct wins
+	fields := sf.fields[:0]
+	for _, f := range sf.fields {
+		if sf.byColumn[f.column] != f {
+			continue
+		}
+
+		fields = append(fields, f)
+		sf.columns = append(sf.columns, f.column)
+		if len(sf.primaryKey) == 0 && !f.readOnly && f.options.contains(primaryKeyOption) {
+			sf.primaryKey = f.column
+		}
+	}
+	sf.fields = fields
+
+	// Only keep the columns that are mapped into the pointer
+	pointers := sf.pointers[:0]
+	for _, p := range sf.pointers {
+		columns := p.columns[:0]
+		for _, column := range p.columns {
+			if hasIndexPrefix(sf.byColumn[column].index, p.index) {
+				columns = append(columns, column)
+			}
+		}
+
+		if len(columns) > 0 {
+			p.columns = columns
+			pointers = append(pointers, p)
+		}
+	}
+	sf.pointers = pointers
+
+	return sf
+}
+
+func (sf *structFields) add(typeOf reflect.Type, index []int, prefix string, readOnly bool) {
+	// A struct that refers to its own type, like the parent of a category,
+	// is nested once into itself, the deeper levels would never end
+	if sf.path[typeOf] > 1 {
+		return
+	}
+	sf.path[typeOf]++
+	defer func() { sf.path[typeOf]-- }()
+
+	for i := 0; i < typeOf.NumField(); i++ {
+		structField := typeOf.Field(i)
+		column, opts := parseTag(structField.Tag.Get(scannerTag))
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		// Flatten embedded structs without a tag
+		if len(column) == 0 {
+			if structField.Anonymous && isEmbedded(structField.Type) {
+				sf.addNested(structField.Type, fieldIndex, prefix, readOnly)
+			}
+			continue
+		}
+
+		if !opts.contains(jsonOption) {
+			if p, ok := opts.value(prefixOption); ok && isEmbedded(structField.Type) {
+				sf.addNested(structField.Type, fieldIndex, prefix+p, true)
+				continue
+			}
+
+			if isNested(structField.Type) {
+				sf.addNested(structField.Type, fieldIndex, prefix, true)
+				continue
+			}
+		}
+
+		column = prefix + column
+		f := &field{
+			name:     structField.Name,
+			column:   column,
+			index:    fieldIndex,
+			typ:      structField.Type,
+			options:  opts,
+			keepZero: opts.contains(keepZeroOption),
+			nullable: isNullable(structField.Type),
+			readOnly: readOnly,
+			joined:   len(prefix) > 0,
+			convert:  converterFor(structField.Type),
+			value:    valueFor(structField.Type),
+		}
+		if opts.contains(jsonOption) {
+			f.convert, f.value = setJSON, jsonValue
+		} else if opts.contains(arrayOption) {
+			f.convert, f.value = setArray, arrayValue
+		}
+		sf.fields = append(sf.fields, f)
+
+		if existing, ok := sf.byColumn[column]; !ok || len(fieldIndex) < len(existing.index) {
+			sf.byColumn[column] = f
+		}
+	}
+}
+
+// addNested adds the fields of the struct or the pointer to a struct
+func (sf *structFields) addNested(typeOf reflect.Type, index []int, prefix string, readOnly bool) {
+	if typeOf.Kind() != reflect.Ptr {
+		sf.add(typeOf, index, prefix, readOnly)
+		return
+	}
+
+	start := len(sf.fields)
+	sf.add(typeOf.Elem(), index, prefix, readOnly)
+
+	p := &nestedPointer{index: index}
+	for _, f := range sf.fields[start:] {
+		p.columns = append(p.columns, f.column)
+	}
+	sf.pointers = append(sf.pointers, p)
+}
+
+// isNested checks if the columns of the field are the fields of the
+// struct it points to, unless the struct maps the value itself
+func isNested(typeOf reflect.Type) bool {
+	if typeOf.Kind() != reflect.Ptr || typeOf.Elem().Kind() != reflect.Struct {
+		return false
+	}
+
+	if typeOf.Elem() == timeType || typeOf.Elem() == ratType {
+		return false
+	}
+	return !typeOf.Implements(scannerInterface) && !typeOf.Implements(valuerInterface)
+}
+
+// isEmbedded checks if the columns of the struct or pointer to a struct
+// are its fields, unless the struct maps the value itself
+func isEmbedded(typeOf reflect.Type) bool {
+	if typeOf.Kind() == reflect.Struct {
+		return isNested(reflect.PtrTo(typeOf))
+	}
+	return isNested(typeOf)
+}
+
+// indirect returns the type the pointer points to
+func indirect(typeOf reflect.Type) reflect.Type {
+	if typeOf.Kind() == reflect.Ptr {
+		return typeOf.Elem()
+	}
+	return typeOf
+}
+
+// isNullable checks if a NULL value can be set on the field
+func isNullable(typeOf reflect.Type) bool {
+	switch typeOf.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return true
+	}
+	return reflect.PtrTo(typeOf).Implements(scannerInterface)
+}
+
+// fieldByIndex returns the field of the struct to scan into,
+// nil pointers to a struct on the way are allocated
+func fieldByIndex(valOf reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && valOf.Kind() == reflect.Ptr {
+			if valOf.IsNil() {
+				if !valOf.CanSet() {
+					return reflect.Value{}
+				}
+				valOf.Set(reflect.New(valOf.Type().Elem()))
+			}
+			valOf = valOf.Elem()
+		}
+		valOf = valOf.Field(x)
+	}
+
+	return valOf
+}
+
+// hasIndexPrefix checks if the field index is within the struct at the prefix index
+func hasIndexPrefix(index, prefix []int) bool {
+	if len(index) <= len(prefix) {
+		return false
+	}
+
+	for i := range prefix {
+		if index[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// fieldValue returns the field of the struct to write,
+// it's false when a pointer to a struct on the way is nil
+func fieldValue(valOf reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && valOf.Kind() == reflect.Ptr {
+			if valOf.IsNil() {
+				return reflect.Value{}, false
+			}
+			valOf = valOf.Elem()
+		}
+		valOf = valOf.Field(x)
+	}
+
+	return valOf, true
+}
@@ -0,0 +1,195 @@
+package fluent
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nestedTest struct {
+	ID      int       `sql:"id"`
+	Scan    *scanTest `sql:"scan"`
+	Inherit *Inherit  `sql:"inherit"`
+	Skipped string
+}
+
+type Timestamps struct {
+	CreatedAt time.Time  `sql:"created_at"`
+	DeletedAt *time.Time `sql:"deleted_at"`
+}
+
+type authorTest struct {
+	ID   int    `sql:"id"`
+	Name string `sql:"name"`
+}
+
+type postTest struct {
+	Timestamps
+	ID     int         `sql:"id"`
+	Title  string      `sql:"title"`
+	Author authorTest  `sql:"author,prefix=author_"`
+	Editor *authorTest `sql:"editor,prefix=editor_"`
+	Skip   authorTest
+}
+
+type categoryTest struct {
+	ID     int           `sql:"id"`
+	Name   string        `sql:"name"`
+	Parent *categoryTest `sql:"parent,prefix=parent_"`
+}
+
+type managerTest struct {
+	ID      int          `sql:"id"`
+	Manager *managerTest `sql:"manager"`
+}
+
+func Test_GetStructFields(t *testing.T) {
+	require := require.New(t)
+
+	sf := getStructFields(reflect.TypeOf(nestedTest{}))
+	// The row_count of Inherit wins from the deeper row_count of Scan
+	require.Equal([]string{"id", "name", "total", "is_active", "created_at", "row_count"}, sf.columns)
+	require.Equal([]int{0}, sf.byColumn["id"].index)
+	require.Equal([]int{2, 0}, sf.byColumn["row_count"].index)
+	require.Equal([]int{1, 2}, sf.byColumn["name"].index)
+	require.Equal(sf.columns, getColumns(reflect.TypeOf(nestedTest{})))
+
+	var wg sync.WaitGroup
+	results := make([]*structFields, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = getStructFields(reflect.TypeOf(nestedTest{}))
+		}(i)
+	}
+	wg.Wait()
+
+	for _, result := range results {
+		require.True(sf == result)
+	}
+}
+
+func Test_NestedStructFields(t *testing.T) {
+	require := require.New(t)
+
+	record := nestedTest{}
+	err := scanStruct(&record, map[string]interface{}{"id": 1, "name": "gerald", "row_count": 2})
+	require.Nil(err)
+	require.Equal(1, record.ID)
+	require.Equal("gerald", record.Scan.Name)
+	require.Equal(2, record.Inherit.RowCount)
+
+	// The tagged pointers to a struct are only scanned
+	cols, args, err := getStructValues(record)
+	require.Nil(err)
+	require.Equal([]string{"id"}, cols)
+	require.Equal([]interface{}{1}, args)
+
+	cols, _, err = getStructValues(nestedTest{ID: 1})
+	require.Nil(err)
+	require.Equal([]string{"id"}, cols)
+}
+
+func Test_EmbeddedStructFields(t *testing.T) {
+	require := require.New(t)
+
+	sf := getStructFields(reflect.TypeOf(postTest{}))
+	require.Equal([]string{
+		"created_at", "deleted_at", "id", "title",
+		"author_id", "author_name", "editor_id", "editor_name",
+	}, sf.columns)
+	require.Equal([]int{0, 0}, sf.byColumn["created_at"].index)
+	require.Equal([]int{3, 1}, sf.byColumn["author_name"].index)
+
+	timestamp := time.Now()
+	record := postTest{}
+	err := scanStruct(&record, map[string]interface{}{
+		"created_at":  timestamp,
+		"id":          int64(1),
+		"author_id":   int64(2),
+		"author_name": "gerald",
+		"editor_id":   int64(3),
+	})
+	require.Nil(err)
+	require.Equal(postTest{
+		Timestamps: Timestamps{CreatedAt: timestamp},
+		ID:         1,
+		Author:     authorTest{ID: 2, Name: "gerald"},
+		Editor:     &authorTest{ID: 3},
+	}, record)
+
+	// The prefixed columns of the joined tables aren't written
+	cols, args, err := getStructValues(record)
+	require.Nil(err)
+	require.Equal([]string{"created_at", "id"}, cols)
+	require.Equal([]interface{}{timestamp, 1}, args)
+
+	_, err = getColumnValues(record, []string{"author_name"})
+	require.NotNil(err)
+
+	cols, _, err = getStructValues(postTest{Title: "post"})
+	require.Nil(err)
+	require.Equal([]string{"title"}, cols)
+
+	// A left join without a match leaves the pointer nil
+	vals := map[string]interface{}{"id": int64(1), "editor_id": nil, "editor_name": nil}
+	record = postTest{}
+	require.Nil(scanStruct(&record, vals))
+	require.Nil(record.Editor)
+
+	// In strict mode the fields of the nil pointer aren't checked
+	require.Nil((&one{strict: true}).scan(&record, nil, vals))
+	require.Nil(record.Editor)
+	err = checkNull(&record, map[string]interface{}{"editor_id": nil, "editor_name": "gerald"})
+	require.True(errors.Is(err, ErrNullValue))
+	err = checkNull(&record, map[string]interface{}{"author_id": nil, "author_name": nil})
+	require.True(errors.Is(err, ErrNullValue))
+
+	// The pointer of a reused struct is set back to nil
+	record.Editor = &authorTest{ID: 3}
+	require.Nil(scanStruct(&record, vals))
+	require.Nil(record.Editor)
+
+	record.Editor = &authorTest{ID: 3}
+	require.Nil(scanStruct(&record, map[string]interface{}{"editor_id": nil, "editor_name": "gerald"}))
+	require.Equal(&authorTest{Name: "gerald"}, record.Editor)
+
+	require.Equal([]*nestedPointer{{index: []int{4}, columns: []string{"editor_id", "editor_name"}}}, sf.pointers)
+}
+
+func Test_SelfReferencingStructFields(t *testing.T) {
+	require := require.New(t)
+
+	// The struct is nested once into itself
+	sf := getStructFields(reflect.TypeOf(categoryTest{}))
+	require.Equal([]string{"id", "name", "parent_id", "parent_name"}, sf.columns)
+
+	record := categoryTest{}
+	err := scanStruct(&record, map[string]interface{}{
+		"id":          int64(2),
+		"name":        "child",
+		"parent_id":   int64(1),
+		"parent_name": "root",
+	})
+	require.Nil(err)
+	require.Equal(categoryTest{ID: 2, Name: "child", Parent: &categoryTest{ID: 1, Name: "root"}}, record)
+
+	cols, args, err := getStructValues(record)
+	require.Nil(err)
+	require.Equal([]string{"id", "name"}, cols)
+	require.Equal([]interface{}{2, "child"}, args)
+
+	// Without a prefix the columns of the pointer are shadowed by the outer ones
+	sf = getStructFields(reflect.TypeOf(managerTest{}))
+	require.Equal([]string{"id"}, sf.columns)
+	require.Empty(sf.pointers)
+
+	cols, _, err = getStructValues(managerTest{ID: 1})
+	require.Nil(err)
+	require.Equal([]string{"id"}, cols)
+}
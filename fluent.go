@@ -1,13 +1,32 @@
 package fluent
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/lib/pq"
+)
 
 // Fluent is the struct that holds
 // the database connection and
 // the query information
 type Fluent struct {
 	db    *sql.DB
+	tx    *sql.Tx
+	ctx   context.Context
 	query *query
+	stmts *stmtCache
+}
+
+// executor is implemented by both *sql.DB and *sql.Tx
+type executor interface {
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // Mapper exposes the functionalities
@@ -16,45 +35,109 @@ type Mapper interface {
 	Table(table string) QueryMapper
 	GetDB() *sql.DB
 	Debug(status bool) Mapper
+	Strict(status bool) Mapper
+	Begin() (TxMapper, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (TxMapper, error)
+	Transaction(ctx context.Context, fn func(Mapper) error) error
+	RetryTransaction(ctx context.Context, policy RetryPolicy, fn func(Mapper) error) error
+	CopyFrom(ctx context.Context, table string, columns []string, source interface{}) (CopyResult, error)
+	StmtCache(size int) Mapper
+	StmtCacheStats() StmtCacheStats
 }
 
 // QueryMapper exposes the functionalities
 // to build the query
 type QueryMapper interface {
+	WithContext(ctx context.Context) QueryMapper
 	Join(table, column1, column2 string) QueryMapper
 	LeftJoin(table, column1, column2 string) QueryMapper
 	Where(column, operator string, value interface{}) QueryMapper
+	OrWhere(column, operator string, value interface{}) QueryMapper
 	WhereNull(column string, isNull bool) QueryMapper
+	OrWhereNull(column string, isNull bool) QueryMapper
+	WhereIn(column string, values interface{}) QueryMapper
+	WhereNotIn(column string, values interface{}) QueryMapper
+	WhereBetween(column string, from, to interface{}) QueryMapper
+	WhereLike(column, pattern string) QueryMapper
+	WhereILike(column, pattern string) QueryMapper
+	WhereJSON(column, key, operator string, value interface{}) QueryMapper
+	WhereJSONText(column, key, operator string, value interface{}) QueryMapper
+	WhereJSONContains(column string, value interface{}) QueryMapper
+	WhereJSONHasKey(column, key string) QueryMapper
+	WhereAny(column string, value interface{}) QueryMapper
+	WhereContains(column string, values interface{}) QueryMapper
+	WhereOverlaps(column string, values interface{}) QueryMapper
+	WhereGroup(group func(QueryMapper)) QueryMapper
+	OrWhereGroup(group func(QueryMapper)) QueryMapper
 	OrderBy(columns ...string) QueryMapper
 	GroupBy(columns ...string) QueryMapper
 	Limit(limit int) QueryMapper
 	Offset(offset int) QueryMapper
+	Unfiltered() QueryMapper
+	OnConstraint(name string) QueryMapper
+	DoNothing() QueryMapper
+	DoUpdate(columns ...string) QueryMapper
+	DoUpdateSet(column, expression string) QueryMapper
 	Get(columns ...string) ScanMapper
+	Pluck(column string, dest interface{}) error
+	PluckMap(key, value string, dest interface{}) error
 	ExecuteMapper
+	AggregateMapper
 }
 
-// ScanMapper exposes the functionalities to
-// scan and fetch the rows
+// ScanMapper exposes the functionalities to scan and fetch
+// the rows into a struct or a map[string]interface{}
 type ScanMapper interface {
 	One(s interface{}) error
 	All(s interface{}) error
+	Each(s interface{}, fn func() error) error
+	Cursor() (*Cursor, error)
 }
 
 // ExecuteMapper exposes the functionalities
 // to execute the query
 type ExecuteMapper interface {
 	Insert(s interface{}) (int, error)
+	InsertReturning(s interface{}, dest interface{}) error
+	Upsert(s interface{}, conflictTarget ...string) (int, bool, error)
+	InsertMany(s interface{}) ([]int, error)
 	Update(s interface{}) error
+	UpdateColumns(s interface{}, columns ...string) error
+	Delete() error
+	DeleteReturning(columns ...string) ScanMapper
+}
+
+// AggregateMapper exposes the functionalities
+// to fetch the result of an aggregate function
+type AggregateMapper interface {
+	Count() (int64, error)
+	Sum(column string) (float64, error)
+	Avg(column string) (float64, error)
+	Min(column string, dest interface{}) error
+	Max(column string, dest interface{}) error
+	Exists() (bool, error)
 }
 
 // New set the DB connection and query struct
 func New(db *sql.DB) Mapper {
-	return &Fluent{db, newQuery()}
+	return &Fluent{db, nil, context.Background(), newQuery(), nil}
 }
 
 // clone the fluent struct for concurrent use
 func (f *Fluent) clone() *Fluent {
-	return &Fluent{f.db, newQuery()}
+	query := newQuery()
+	query.debug = f.query.isDebug()
+	query.strict = f.query.isStrict()
+	return &Fluent{f.db, f.tx, f.ctx, query, f.stmts}
+}
+
+// executor returns the transaction if one is in progress
+// otherwise the database connection
+func (f *Fluent) executor() executor {
+	if f.tx != nil {
+		return f.tx
+	}
+	return f.db
 }
 
 // Debug if set to true it will log the query
@@ -63,6 +146,14 @@ func (f *Fluent) Debug(status bool) Mapper {
 	return f
 }
 
+// Strict if set to true scanning a NULL value into a struct field
+// that can't be NULL returns ErrNullValue, by default the field
+// is set to its zero value
+func (f *Fluent) Strict(status bool) Mapper {
+	f.query.builder(setStrict(status))
+	return f
+}
+
 // GetDB returns the database connection
 func (f *Fluent) GetDB() *sql.DB {
 	return f.db
@@ -75,6 +166,13 @@ func (f *Fluent) Table(table string) QueryMapper {
 	return f
 }
 
+// WithContext set the context used to prepare and execute
+// the query, the provided context must be non-nil
+func (f *Fluent) WithContext(ctx context.Context) QueryMapper {
+	f.ctx = ctx
+	return f
+}
+
 // Join set the table and columns for the join query
 func (f *Fluent) Join(table, column1, column2 string) QueryMapper {
 	join := []interface{}{table, column1, column2}
@@ -103,6 +201,149 @@ func (f *Fluent) WhereNull(column string, isNull bool) QueryMapper {
 	return f
 }
 
+// OrWhere set the column, operator and the value for
+// the where clause joined by OR
+func (f *Fluent) OrWhere(column, operator string, value interface{}) QueryMapper {
+	where := []interface{}{column, operator, value}
+	f.query.builder(setOrWhere(where))
+	return f
+}
+
+// OrWhereNull set if the column is null or not null joined by OR
+func (f *Fluent) OrWhereNull(column string, isNull bool) QueryMapper {
+	where := []interface{}{column, isNull}
+	f.query.builder(setOrWhereNull(where))
+	return f
+}
+
+// WhereIn set the column and the values the column should match,
+// a slice is expanded to a list of placeholders
+func (f *Fluent) WhereIn(column string, values interface{}) QueryMapper {
+	where := []interface{}{column, inClause, values}
+	f.query.builder(setWhereIn(where))
+	return f
+}
+
+// WhereNotIn set the column and the values the column shouldn't match,
+// a slice is expanded to a list of placeholders
+func (f *Fluent) WhereNotIn(column string, values interface{}) QueryMapper {
+	where := []interface{}{column, notInClause, values}
+	f.query.builder(setWhereIn(where))
+	return f
+}
+
+// WhereBetween set the column and the range the value should be in
+func (f *Fluent) WhereBetween(column string, from, to interface{}) QueryMapper {
+	where := []interface{}{column, from, to}
+	f.query.builder(setWhereBetween(where))
+	return f
+}
+
+// WhereLike set the column and the pattern it should match
+func (f *Fluent) WhereLike(column, pattern string) QueryMapper {
+	where := []interface{}{column, likeClause, pattern}
+	f.query.builder(setWhere(where))
+	return f
+}
+
+// WhereILike set the column and the pattern it should match case insensitive
+func (f *Fluent) WhereILike(column, pattern string) QueryMapper {
+	where := []interface{}{column, iLikeClause, pattern}
+	f.query.builder(setWhere(where))
+	return f
+}
+
+// WhereJSON set the key of the JSON column, the operator and the value
+// for the where clause, the value is marshalled and compared as JSON:
+// WhereJSON("settings", "theme", "=", "dark") is settings -> 'theme' = '"dark"'
+func (f *Fluent) WhereJSON(column, key, operator string, value interface{}) QueryMapper {
+	data, err := json.Marshal(value)
+	if err != nil {
+		f.query.builder(setError(err))
+		return f
+	}
+
+	where := []interface{}{column, jsonClause, key, operator, string(data)}
+	f.query.builder(setWhereJSON(where))
+	return f
+}
+
+// WhereJSONText set the key of the JSON column, the operator and the value
+// for the where clause, the value of the key is compared as text:
+// WhereJSONText("settings", "theme", "=", "dark") is settings ->> 'theme' = 'dark'
+func (f *Fluent) WhereJSONText(column, key, operator string, value interface{}) QueryMapper {
+	where := []interface{}{column, jsonTextClause, key, operator, value}
+	f.query.builder(setWhereJSON(where))
+	return f
+}
+
+// WhereJSONContains set the JSON column and the value it should contain,
+// the value is marshalled to JSON
+func (f *Fluent) WhereJSONContains(column string, value interface{}) QueryMapper {
+	data, err := json.Marshal(value)
+	if err != nil {
+		f.query.builder(setError(err))
+		return f
+	}
+
+	where := []interface{}{column, containsClause, string(data)}
+	f.query.builder(setWhere(where))
+	return f
+}
+
+// WhereJSONHasKey set the JSON column and the top level key it should have
+func (f *Fluent) WhereJSONHasKey(column, key string) QueryMapper {
+	where := []interface{}{column, hasKeyClause, key}
+	f.query.builder(setWhere(where))
+	return f
+}
+
+// WhereAny set the array column and the value it should hold:
+// WhereAny("tags", "go") is $1 = ANY(tags)
+func (f *Fluent) WhereAny(column string, value interface{}) QueryMapper {
+	where := []interface{}{column, value}
+	f.query.builder(setWhereAny(where))
+	return f
+}
+
+// WhereContains set the array column and the slice of values it
+// should contain: WhereContains("tags", []string{"go"}) is tags @> $1
+func (f *Fluent) WhereContains(column string, values interface{}) QueryMapper {
+	where := []interface{}{column, containsClause, pq.Array(values)}
+	f.query.builder(setWhere(where))
+	return f
+}
+
+// WhereOverlaps set the array column and the slice of values it should have
+// at least one in common with: WhereOverlaps("tags", []string{"go"}) is tags && $1
+func (f *Fluent) WhereOverlaps(column string, values interface{}) QueryMapper {
+	where := []interface{}{column, overlapClause, pq.Array(values)}
+	f.query.builder(setWhere(where))
+	return f
+}
+
+// WhereGroup wraps the where clauses set in the group between parentheses
+func (f *Fluent) WhereGroup(group func(QueryMapper)) QueryMapper {
+	f.query.builder(setWhereGroup(f.group(group)))
+	return f
+}
+
+// OrWhereGroup wraps the where clauses set in the group
+// between parentheses joined by OR
+func (f *Fluent) OrWhereGroup(group func(QueryMapper)) QueryMapper {
+	f.query.builder(setOrWhereGroup(f.group(group)))
+	return f
+}
+
+// group collects the where clauses set in the group function,
+// an error of the group is set on the query
+func (f *Fluent) group(group func(QueryMapper)) []condition {
+	g := f.clone()
+	group(g)
+	f.query.builder(setError(g.query.err))
+	return g.query.where
+}
+
 // OrderBy set to columns to order by
 func (f *Fluent) OrderBy(columns ...string) QueryMapper {
 	f.query.builder(setOrderBy(columns))
@@ -127,6 +368,39 @@ func (f *Fluent) Offset(offset int) QueryMapper {
 	return f
 }
 
+// Unfiltered allows a delete to run without a where clause
+func (f *Fluent) Unfiltered() QueryMapper {
+	f.query.builder(setUnfiltered(true))
+	return f
+}
+
+// OnConstraint set the constraint name of the upsert conflict
+func (f *Fluent) OnConstraint(name string) QueryMapper {
+	f.query.builder(setOnConstraint(name))
+	return f
+}
+
+// DoNothing skips the insert of the upsert on a conflict
+func (f *Fluent) DoNothing() QueryMapper {
+	f.query.builder(setDoNothing())
+	return f
+}
+
+// DoUpdate set the columns to update with the
+// excluded values of the upsert on a conflict
+func (f *Fluent) DoUpdate(columns ...string) QueryMapper {
+	f.query.builder(setDoUpdate(columns))
+	return f
+}
+
+// DoUpdateSet set the column to update with the SQL
+// expression of the upsert on a conflict, for example:
+// DoUpdateSet("total", "test.total + EXCLUDED.total")
+func (f *Fluent) DoUpdateSet(column, expression string) QueryMapper {
+	f.query.builder(setDoUpdateSet(column, expression))
+	return f
+}
+
 // Get set the columns to select from and build the query
 func (f *Fluent) Get(columns ...string) ScanMapper {
 	f.query.builder(
@@ -134,30 +408,156 @@ func (f *Fluent) Get(columns ...string) ScanMapper {
 		buildSelect(),
 		buildJoin(),
 		buildLeftJoin(),
+		buildWhere(),
 		buildGroupBy(),
 		buildOrderBy(),
-		buildWhere(),
-		buildWhereNull(),
 		buildOffset(),
 		buildLimit(),
 	)
 	return f
 }
 
-// Insert a record by building the query and scanning
-// the values from the struct to insert
+// Pluck fetch a single column of all the records into a slice
+func (f *Fluent) Pluck(column string, dest interface{}) error {
+	f.Get(column)
+	return f.scan(dest, &pluck{})
+}
+
+// PluckMap fetch two columns of all the records into
+// a map with the first column as key and the second as value
+func (f *Fluent) PluckMap(key, value string, dest interface{}) error {
+	f.Get(key, value)
+	return f.scan(dest, &pluckMap{})
+}
+
+// Insert a record by building the query and scanning the values from the struct
+// to insert, it returns ErrPrimaryKeyType when the primary key isn't an integer
 func (f *Fluent) Insert(s interface{}) (int, error) {
+	if err := checkPrimaryKey(s); err != nil {
+		return 0, err
+	}
+
 	cols, args, err := getStructValues(s)
 	if err != nil {
 		return 0, err
 	}
 
-	f.query.builder(buildInsert(cols, args))
-	return f.queryRow()
+	var id int
+	f.query.builder(
+		setPrimaryKey(getPrimaryKey(s)),
+		buildInsert(cols, args),
+	)
+	err = f.queryRow(&id)
+	return id, err
+}
+
+// InsertReturning inserts a record and scans all the columns of the
+// inserted record into dest, including the columns generated by the
+// database like defaults and serials. Use it for primary keys that
+// aren't integers, dest can be the inserted struct itself
+func (f *Fluent) InsertReturning(s interface{}, dest interface{}) error {
+	cols, args, err := getStructValues(s)
+	if err != nil {
+		return err
+	}
+
+	f.query.builder(buildInsertReturning(cols, args))
+	return f.scan(dest, &one{f.query.strict})
 }
 
-// Update a record by building the query and scanning
-// the values from the struct to update
+// InsertMany inserts a slice of records with a multi row insert and returns
+// the ids in the order of the slice. Columns that are zero for some records
+// are inserted as DEFAULT. The records are split into multiple statements
+// to stay under the parameter limit of Postgres, these are executed
+// in a transaction when not already in one
+func (f *Fluent) InsertMany(s interface{}) ([]int, error) {
+	if err := checkPrimaryKey(s); err != nil {
+		return nil, err
+	}
+
+	cols, rows, err := getSliceValues(s)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	if len(cols) == 0 {
+		return nil, fmt.Errorf("The provided records don't have any values to insert")
+	}
+
+	pk := getPrimaryKey(s)
+	size := maxParameters / len(cols)
+	if len(rows) <= size || f.tx != nil {
+		return f.insertMany(f.query.table, pk, cols, rows, size)
+	}
+
+	var ids []int
+	err = f.Transaction(f.ctx, func(tx Mapper) error {
+		ids, err = tx.(*Fluent).insertMany(f.query.table, pk, cols, rows, size)
+		return err
+	})
+	return ids, err
+}
+
+// insertMany inserts the rows in chunks of the given size
+func (f *Fluent) insertMany(table, pk string, cols []string, rows [][]interface{}, size int) ([]int, error) {
+	ids := make([]int, 0, len(rows))
+	for start := 0; start < len(rows); start += size {
+		end := start + size
+		if end > len(rows) {
+			end = len(rows)
+		}
+
+		chunk := f.clone()
+		chunk.query.builder(
+			setTable(table),
+			setPrimaryKey(pk),
+			buildInsertMany(cols, rows[start:end]),
+		)
+
+		chunkIDs, err := chunk.queryIDs()
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, chunkIDs...)
+	}
+
+	return ids, nil
+}
+
+// Upsert inserts a record or updates the existing record on a conflict
+// with the conflict target. It returns the id of the record and true when
+// the record was inserted. When DoNothing is set and the record
+// already exists no id is returned, without DoNothing a conflict
+// target or OnConstraint is required
+func (f *Fluent) Upsert(s interface{}, conflictTarget ...string) (int, bool, error) {
+	if err := checkPrimaryKey(s); err != nil {
+		return 0, false, err
+	}
+
+	cols, args, err := getStructValues(s)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var (
+		id       int
+		inserted bool
+	)
+	f.query.builder(
+		setPrimaryKey(getPrimaryKey(s)),
+		setConflictTarget(conflictTarget),
+		buildUpsert(cols, args),
+	)
+
+	err = f.queryRow(&id, &inserted)
+	if err == ErrNoRows && f.query.conflict.doNothing {
+		return 0, false, nil
+	}
+	return id, inserted, err
+}
+
+// Update a record by building the query and scanning the values from
+// the struct to update, it returns ErrNoColumns when all values are zero
 func (f *Fluent) Update(s interface{}) error {
 	cols, args, err := getStructValues(s)
 	if err != nil {
@@ -167,95 +567,214 @@ func (f *Fluent) Update(s interface{}) error {
 	f.query.builder(
 		buildUpdate(cols, args),
 		buildWhere(),
-		buildWhereNull(),
 	)
 
 	return f.execute()
 }
 
+// UpdateColumns updates exactly the given columns with the values
+// of the struct, including zero values. At least one column is required
+func (f *Fluent) UpdateColumns(s interface{}, columns ...string) error {
+	args, err := getColumnValues(s, columns)
+	if err != nil {
+		return err
+	}
+
+	f.query.builder(
+		buildUpdate(columns, args),
+		buildWhere(),
+	)
+
+	return f.execute()
+}
+
+// Delete the records matching the where clauses
+func (f *Fluent) Delete() error {
+	f.query.builder(
+		buildDelete(),
+		buildWhere(),
+	)
+
+	return f.execute()
+}
+
+// DeleteReturning deletes the records matching the where clauses and returns
+// the given columns of the deleted records, all columns when none are given
+func (f *Fluent) DeleteReturning(columns ...string) ScanMapper {
+	f.query.builder(
+		setColumns(columns),
+		buildDelete(),
+		buildWhere(),
+		buildReturning(),
+	)
+	return f
+}
+
+// Count returns the number of records, or the number of groups when GroupBy is set
+func (f *Fluent) Count() (int64, error) {
+	var count int64
+	f.query.builder(buildAggregate(countClause, "*"))
+	err := f.queryRow(&count)
+	return count, err
+}
+
+// Sum returns the sum of the column, 0 when there are no records
+func (f *Fluent) Sum(column string) (float64, error) {
+	return f.aggregate(sumClause, column)
+}
+
+// Avg returns the average of the column, 0 when there are no records
+func (f *Fluent) Avg(column string) (float64, error) {
+	return f.aggregate(avgClause, column)
+}
+
+// Min scans the minimum of the column into dest, like a number, a string or
+// a time.Time. It's set to the zero value when there are no records
+func (f *Fluent) Min(column string, dest interface{}) error {
+	return f.aggregateInto(minClause, column, dest)
+}
+
+// Max scans the maximum of the column into dest, like a number, a string or
+// a time.Time. It's set to the zero value when there are no records
+func (f *Fluent) Max(column string, dest interface{}) error {
+	return f.aggregateInto(maxClause, column, dest)
+}
+
+// Exists checks if there is at least one record
+func (f *Fluent) Exists() (bool, error) {
+	var exists bool
+	f.query.builder(buildExists())
+	err := f.queryRow(&exists)
+	return exists, err
+}
+
+// aggregate returns the result of the aggregate function over the column
+func (f *Fluent) aggregate(function, column string) (float64, error) {
+	var result sql.NullFloat64
+	f.query.builder(buildAggregate(function, column))
+	err := f.queryRow(&result)
+	return result.Float64, err
+}
+
+// aggregateInto scans the result of the aggregate function over the column into dest
+func (f *Fluent) aggregateInto(function, column string, dest interface{}) error {
+	valOf := reflect.ValueOf(dest)
+	if valOf.Kind() != reflect.Ptr || valOf.IsNil() {
+		return fmt.Errorf("The provided type is not a pointer")
+	}
+
+	result := &scanner{}
+	f.query.builder(buildAggregate(function, column))
+	if err := f.queryRow(result); err != nil {
+		return err
+	}
+
+	field := valOf.Elem()
+	if result.value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	return setFieldValue(field, result.value)
+}
+
 // One fetch a single record
 func (f *Fluent) One(s interface{}) error {
-	st := &one{}
+	st := &one{f.query.strict}
 	return f.scan(s, st)
 }
 
 // All fetch all the records
 func (f *Fluent) All(s interface{}) error {
-	st := &all{}
+	st := &all{f.query.strict}
 	return f.scan(s, st)
 }
 
 func (f *Fluent) execute() error {
+	if f.query.err != nil {
+		return f.query.err
+	}
 	defer f.query.log()
 
-	prepare, err := f.db.Prepare(f.query.stmt)
-	if err != nil {
+	stmt, err := f.statement(func(stmt *sql.Stmt) error {
+		_, err := stmt.ExecContext(f.ctx, f.query.args...)
 		return err
+	})
+	if err == nil {
+		stmt.Close()
 	}
-	defer prepare.Close()
-
-	_, err = prepare.Exec(f.query.args...)
-	return err
+	return wrapError(err)
 }
 
-// queryRow is used to return the last inserted id
-func (f *Fluent) queryRow() (int, error) {
+// queryRow scans the returned columns of a single row into dest
+func (f *Fluent) queryRow(dest ...interface{}) error {
+	if f.query.err != nil {
+		return f.query.err
+	}
 	defer f.query.log()
 
-	var id int
-	prepare, err := f.db.Prepare(f.query.stmt)
-	if err != nil {
-		return id, err
+	stmt, err := f.statement(func(stmt *sql.Stmt) error {
+		return stmt.QueryRowContext(f.ctx, f.query.args...).Scan(dest...)
+	})
+	if err == nil {
+		stmt.Close()
 	}
-	defer prepare.Close()
-
-	err = prepare.QueryRow(f.query.args...).Scan(&id)
-	return id, err
+	return wrapError(err)
 }
 
-// scan prepares the statement and scans the values of each row
-// into the provided struct or slice
-func (f *Fluent) scan(s interface{}, st scannerType) error {
+// queryIDs returns the ids of all the returned rows
+func (f *Fluent) queryIDs() ([]int, error) {
 	defer f.query.log()
 
-	prepare, err := f.db.Prepare(f.query.stmt)
-	if err != nil {
-		return err
-	}
-	defer prepare.Close()
-
-	rows, err := prepare.Query(f.query.args...)
-	if err != nil {
+	var rows *sql.Rows
+	stmt, err := f.statement(func(stmt *sql.Stmt) (err error) {
+		rows, err = stmt.QueryContext(f.ctx, f.query.args...)
 		return err
-	}
-	columns, err := rows.Columns()
+	})
 	if err != nil {
-		return err
+		return nil, wrapError(err)
 	}
+	defer stmt.Close()
+	defer rows.Close()
 
-	result := make(map[string]interface{}, len(columns))
+	var ids []int
 	for rows.Next() {
-		row := make([]interface{}, len(columns))
-		for i := range columns {
-			// Scan the row with the custom scanner
-			row[i] = &scanner{}
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
 		}
+		ids = append(ids, id)
+	}
 
-		if err := rows.Scan(row...); err != nil {
-			return err
-		}
+	return ids, wrapError(rows.Err())
+}
 
-		for i, column := range columns {
-			scanner := row[i].(*scanner)
-			result[column] = scanner.value
-		}
+// scan prepares the statement and scans the values of each row
+// into the provided struct or slice
+func (f *Fluent) scan(s interface{}, st scannerType) error {
+	cursor, err := f.Cursor()
+	if err != nil {
+		return err
+	}
+	defer cursor.Close()
+
+	var found bool
+	for cursor.Next() {
+		found = true
 
 		// Based on the provided interface it will either
 		// scan the result to the struct or slice
-		if err := st.scan(s, result); err != nil {
+		if err := cursor.scan(s, st); err != nil {
 			return err
 		}
 	}
 
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	if !found {
+		return st.noRows()
+	}
+
 	return nil
 }
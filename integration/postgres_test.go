@@ -1,13 +1,16 @@
 package integration
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"math/big"
 	"sync"
 	"testing"
 	"time"
 
-	_ "github.com/lib/pq" //postgres package
+	"github.com/lib/pq"
 	"github.com/sebas7dk/fluent"
 	"github.com/stretchr/testify/require"
 )
@@ -24,6 +27,12 @@ type test2 struct {
 	IsActive int `sql:"is_active"`
 }
 
+type test3 struct {
+	Code      string    `sql:"code,pk"`
+	Name      string    `sql:"name"`
+	CreatedAt time.Time `sql:"created_at"`
+}
+
 type joinboth struct {
 	ID       int     `sql:"id"`
 	Name     string  `sql:"name"`
@@ -31,6 +40,21 @@ type joinboth struct {
 	IsActive int     `sql:"is_active"`
 }
 
+type Timestamps struct {
+	CreatedAt time.Time `sql:"created_at"`
+}
+
+type author struct {
+	ID   int    `sql:"id"`
+	Name string `sql:"name"`
+}
+
+type post struct {
+	Timestamps
+	IsActive int    `sql:"is_active"`
+	Author   author `sql:"author,prefix=author_"`
+}
+
 func connect() (fluent.Mapper, error) {
 	connStr := fmt.Sprintf(
 		"postgresql://%s:%s@%s:%d/%s?sslmode=disable",
@@ -75,18 +99,22 @@ func Test_Postgres(t *testing.T) {
 	t.Run("Insert records in table test 2", func(t *testing.T) {
 		require := require.New(t)
 
+		records := []test2{}
 		for i := 1; i <= 10; i++ {
-			record := test2{
+			records = append(records, test2{
 				TestID:   i,
 				IsActive: 0,
-			}
+			})
+		}
 
-			id, err := f.Table("test_2").Insert(record)
-			if err != nil {
-				t.Fatal(err)
-			}
+		ids, err := f.Table("test_2").InsertMany(records)
+		if err != nil {
+			t.Fatal(err)
+		}
 
-			require.True(id > 0)
+		require.Len(ids, 10)
+		for i, id := range ids {
+			require.Equal(i+1, id)
 		}
 	})
 
@@ -113,7 +141,7 @@ func Test_Postgres(t *testing.T) {
 		}
 
 		record = test1{}
-		err = f.Table("test_1").WhereNull("updated_at", false).Get("*").One(&record)
+		err = f.Table("test_1").WhereNull("deleted_at", false).Get("*").One(&record)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -153,6 +181,351 @@ func Test_Postgres(t *testing.T) {
 		}
 	})
 
+	t.Run("Get records with grouped where clauses from table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		records := []test1{}
+		err := f.Table("test_1").
+			WhereGroup(func(q fluent.QueryMapper) {
+				q.Where("id", "=", 1).OrWhere("id", "=", 2)
+			}).
+			WhereNull("updated_at", true).
+			Get("id", "name", "total").
+			All(&records)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		require.Len(records, 2)
+		for _, record := range records {
+			require.Contains([]int{1, 2}, record.ID)
+		}
+	})
+
+	t.Run("Get records matching a list of ids from table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		records := []test1{}
+		err := f.Table("test_1").
+			WhereIn("id", []int{3, 4, 5}).
+			WhereBetween("total", 13.00, 14.00).
+			WhereLike("name", "user_%").
+			Get("id", "name", "total").
+			All(&records)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		require.Len(records, 2)
+		for _, record := range records {
+			require.Contains([]int{3, 4}, record.ID)
+		}
+	})
+
+	t.Run("Get a record with a context from table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		record := test1{}
+		err := f.Table("test_1").WithContext(ctx).Where("id", "=", 1).Get("id", "name").One(&record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(1, record.ID)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+
+		err = f.Table("test_1").WithContext(ctx).Where("id", "=", 1).Get("id", "name").One(&record)
+		require.True(errors.Is(err, context.Canceled))
+	})
+
+	t.Run("Aggregate the records of table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		query := func() fluent.QueryMapper {
+			return f.Table("test_1").WhereBetween("id", 1, 10)
+		}
+
+		count, err := query().Count()
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(int64(10), count)
+
+		sum, err := query().Sum("total")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(155.00, sum)
+
+		avg, err := query().Avg("total")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(15.50, avg)
+
+		var min float64
+		if err := query().Min("total", &min); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(11.00, min)
+
+		var max string
+		if err := query().Max("name", &max); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal("user_9", max)
+
+		var createdAt time.Time
+		if err := query().Min("created_at", &createdAt); err != nil {
+			t.Fatal(err)
+		}
+		require.False(createdAt.IsZero())
+
+		groups, err := query().GroupBy("name").Count()
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(int64(10), groups)
+
+		_, err = query().GroupBy("name").Sum("total")
+		require.True(errors.Is(err, fluent.ErrGroupedAggregate))
+
+		exists, err := query().Where("name", "=", "user_1").Exists()
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.True(exists)
+
+		exists, err = query().Where("name", "=", "unknown").Exists()
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.False(exists)
+	})
+
+	t.Run("Pluck the records of table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		names := []string{}
+		if err := f.Table("test_1").WhereIn("id", []int{1, 2}).Pluck("name", &names); err != nil {
+			t.Fatal(err)
+		}
+		require.ElementsMatch([]string{"user_1", "user_2"}, names)
+
+		totals := map[int]float64{}
+		if err := f.Table("test_1").WhereIn("id", []int{1, 2}).PluckMap("id", "total", totals); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(map[int]float64{1: 11.00, 2: 12.00}, totals)
+
+		records := []map[string]interface{}{}
+		if err := f.Table("test_1").WhereIn("id", []int{1, 2}).Get("id", "name").All(&records); err != nil {
+			t.Fatal(err)
+		}
+		require.Len(records, 2)
+
+		record := map[string]interface{}{}
+		if err := f.Table("test_1").Where("id", "=", 1).Get("id", "name").One(record); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal("user_1", record["name"])
+	})
+
+	t.Run("Iterate over the records of table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		var ids []int
+		record := test1{}
+		err := f.Table("test_1").WhereBetween("id", 1, 10).Get("id", "name").Each(&record, func() error {
+			ids = append(ids, record.ID)
+			if len(ids) == 5 {
+				return fluent.ErrStop
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Len(ids, 5)
+
+		cursor, err := f.Table("test_1").WhereBetween("id", 1, 10).Get("id", "name").Cursor()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cursor.Close()
+
+		var count int
+		for cursor.Next() {
+			if err := cursor.Scan(&record); err != nil {
+				t.Fatal(err)
+			}
+			require.Equal(fmt.Sprintf("user_%d", record.ID), record.Name)
+			count++
+		}
+		require.NoError(cursor.Err())
+		require.Equal(10, count)
+	})
+
+	t.Run("Typed query on table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		ctx := context.Background()
+		records, err := fluent.Query[test1](f, "test_1").WhereBetween("id", 1, 10).All(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Len(records, 10)
+
+		records, err = fluent.Query[test1](f, "test_1").WhereBetween("id", 1, 10).OrderBy("id DESC").Limit(2).All(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal([]int{10, 9}, []int{records[0].ID, records[1].ID})
+
+		record, err := fluent.Query[test1](f, "test_1").Where("id", "=", 3).First(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal("user_3", record.Name)
+
+		_, err = fluent.Query[test1](f, "test_1").Where("id", "=", -1).First(ctx)
+		require.Equal(fluent.ErrNoRows, err)
+	})
+
+	t.Run("Scan and write sql.Null fields in table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		type nullTest struct {
+			ID    sql.NullInt64   `sql:"id"`
+			Name  sql.NullString  `sql:"name"`
+			Total sql.NullFloat64 `sql:"total"`
+		}
+
+		record := nullTest{}
+		if err := f.Table("test_1").Where("id", "=", 3).Get("id", "name", "total").One(&record); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(sql.NullInt64{Int64: 3, Valid: true}, record.ID)
+		require.Equal(sql.NullString{String: "user_3", Valid: true}, record.Name)
+		require.Equal(sql.NullFloat64{Float64: 13, Valid: true}, record.Total)
+
+		tx, err := f.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer tx.Rollback()
+
+		id, err := tx.Table("test_1").Insert(nullTest{Name: sql.NullString{String: "valuer", Valid: true}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		record = nullTest{}
+		if err := tx.Table("test_1").Where("id", "=", id).Get("id", "name").One(&record); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal("valuer", record.Name.String)
+	})
+
+	t.Run("Scan NULL values from table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		type ptrTest struct {
+			ID        int        `sql:"id"`
+			Name      *string    `sql:"name"`
+			DeletedAt *time.Time `sql:"deleted_at"`
+		}
+
+		// The record is reused so the NULL value should reset the field
+		record := ptrTest{}
+		for _, id := range []int{1, 2} {
+			if err := f.Table("test_1").Where("id", "=", id).Get("id", "name", "deleted_at").One(&record); err != nil {
+				t.Fatal(err)
+			}
+			require.Equal(fmt.Sprintf("user_%d", id), *record.Name)
+			require.Equal(id == 1, record.DeletedAt != nil)
+		}
+
+		strict, err := connect()
+		if err != nil {
+			t.Fatal(err)
+		}
+		strict.Strict(true)
+
+		err = strict.Table("test_1").Where("id", "=", 2).Get("id", "deleted_at").One(&test1{})
+		require.True(errors.Is(err, fluent.ErrNullValue))
+	})
+
+	t.Run("Decode numeric and text values from table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		row := map[string]interface{}{}
+		err := f.Table("test_1").
+			Where("id", "=", 1).
+			Get("total", "'01234'::CHAR(5) AS zip", "12345678901234567890.1234567890::NUMERIC(38,10) AS exact").
+			One(&row)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal("11.00", row["total"])
+		require.Equal("01234", row["zip"])
+		require.Equal("12345678901234567890.1234567890", row["exact"])
+
+		record := struct {
+			Total big.Rat `sql:"total"`
+			Exact string  `sql:"exact"`
+		}{}
+		err = f.Table("test_1").Where("id", "=", 1).Get("total", "total::TEXT AS exact").One(&record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal("11.00", record.Total.FloatString(2))
+		require.Equal("11.00", record.Exact)
+
+		type ratTest struct {
+			Name  string  `sql:"name"`
+			Total big.Rat `sql:"total"`
+		}
+
+		id, err := f.Table("test_1").Insert(ratTest{Name: "rat", Total: *big.NewRat(2501, 100)})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		inserted := ratTest{}
+		if err := f.Table("test_1").Where("id", "=", id).Get("name", "total").One(&inserted); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal("25.01", inserted.Total.FloatString(2))
+
+		if err := f.Table("test_1").Where("id", "=", id).Delete(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Join into a prefixed struct", func(t *testing.T) {
+		require := require.New(t)
+
+		record := post{}
+
+		var id = 1
+		err := f.Table("test_2 as t2").
+			Join("test_1 as t1", "t1.id", "t2.test_id").
+			Where("t1.id", "=", id).
+			Get("t2.is_active", "t2.created_at", "t1.id AS author_id", "t1.name AS author_name").
+			One(&record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		require.Equal(author{ID: id, Name: fmt.Sprintf("user_%d", id)}, record.Author)
+		require.False(record.CreatedAt.IsZero())
+	})
+
 	t.Run("Join both test tables", func(t *testing.T) {
 		require := require.New(t)
 
@@ -174,6 +547,402 @@ func Test_Postgres(t *testing.T) {
 
 	})
 
+	t.Run("Upsert a record in table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		id, inserted, err := f.Table("test_1").Upsert(test1{ID: 2, Name: "user_2", Total: 20.00}, "id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(2, id)
+		require.False(inserted)
+
+		record := test1{}
+		if err := f.Table("test_1").Where("id", "=", 2).Get("id", "total").One(&record); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(20.00, record.Total)
+
+		id, inserted, err = f.Table("test_1").DoNothing().Upsert(test1{ID: 2, Name: "user_upsert"}, "id")
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(0, id)
+		require.False(inserted)
+
+		if err := f.Table("test_1").Where("id", "=", 2).Update(test1{Total: 12.00}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Update records with zero values in table test 2", func(t *testing.T) {
+		require := require.New(t)
+
+		type test2KeepZero struct {
+			IsActive int `sql:"is_active,keepzero"`
+		}
+
+		if err := f.Table("test_2").Where("id", "=", 1).Update(test2KeepZero{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.Table("test_2").Where("id", "=", 2).UpdateColumns(test2{}, "is_active"); err != nil {
+			t.Fatal(err)
+		}
+
+		records := []test2{}
+		err := f.Table("test_2").WhereIn("id", []int{1, 2}).Get("is_active").All(&records)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		require.Len(records, 2)
+		for _, record := range records {
+			require.Equal(0, record.IsActive)
+		}
+	})
+
+	t.Run("Delete records from table test 2", func(t *testing.T) {
+		require := require.New(t)
+
+		err := f.Table("test_2").Delete()
+		require.Equal(fluent.ErrUnfilteredDelete, err)
+
+		record := test2{}
+		err = f.Table("test_2").Where("id", "=", 10).DeleteReturning("test_id", "is_active").One(&record)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		require.Equal(10, record.TestID)
+		require.Equal(1, record.IsActive)
+
+		if err := f.Table("test_2").Where("id", "=", 9).Delete(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+}
+
+func Test_Errors(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	require := require.New(t)
+
+	record := test1{}
+	err = f.Table("test_1").Where("id", "=", -1).Get("id").One(&record)
+	require.True(errors.Is(err, fluent.ErrNoRows))
+
+	_, err = f.Table("test_2").Insert(test2{TestID: -1})
+	require.True(errors.Is(err, fluent.ErrForeignKeyViolation))
+
+	var e *fluent.Error
+	require.True(errors.As(err, &e))
+	require.Equal("test_2_test_id_fkey", e.Constraint)
+
+	_, err = f.Table("test_1").Insert(test1{ID: 1, Name: "duplicate"})
+	require.True(errors.Is(err, fluent.ErrUniqueViolation))
+}
+
+func Test_InsertReturning(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	require := require.New(t)
+
+	record := test3{Name: "returning"}
+	if err := f.Table("test_3").InsertReturning(record, &record); err != nil {
+		t.Fatal(err)
+	}
+
+	require.Len(record.Code, 32)
+	require.Equal("returning", record.Name)
+	require.False(record.CreatedAt.IsZero())
+
+	_, err = f.Table("test_3").Insert(test3{Name: "insert"})
+	require.True(errors.Is(err, fluent.ErrPrimaryKeyType))
+}
+
+func Test_JSON(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	require := require.New(t)
+
+	type settings struct {
+		Theme string   `json:"theme"`
+		Tags  []string `json:"tags"`
+	}
+
+	type jsonTest struct {
+		Code     string   `sql:"code,pk"`
+		Name     string   `sql:"name"`
+		Settings settings `sql:"settings,json"`
+	}
+
+	record := jsonTest{Name: "json", Settings: settings{Theme: "dark", Tags: []string{"beta"}}}
+	if err := f.Table("test_3").InsertReturning(record, &record); err != nil {
+		t.Fatal(err)
+	}
+	require.Equal("dark", record.Settings.Theme)
+
+	queries := []fluent.QueryMapper{
+		f.Table("test_3").WhereJSON("settings", "theme", "=", "dark"),
+		f.Table("test_3").WhereJSONText("settings", "theme", "=", "dark"),
+		f.Table("test_3").WhereJSONContains("settings", map[string][]string{"tags": {"beta"}}),
+		f.Table("test_3").WhereJSONHasKey("settings", "tags"),
+	}
+	for _, query := range queries {
+		result := jsonTest{}
+		if err := query.Get("code", "name", "settings").One(&result); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(record, result)
+	}
+
+	exists, err := f.Table("test_3").WhereJSONText("settings", "theme", "=", "light").Exists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.False(exists)
+}
+
+func Test_Array(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	require := require.New(t)
+
+	type arrayTest struct {
+		Code string   `sql:"code,pk"`
+		Name string   `sql:"name"`
+		Tags []string `sql:"tags,array"`
+	}
+
+	record := arrayTest{Name: "array", Tags: []string{"go", "a b"}}
+	if err := f.Table("test_3").InsertReturning(record, &record); err != nil {
+		t.Fatal(err)
+	}
+	require.Equal([]string{"go", "a b"}, record.Tags)
+
+	queries := []fluent.QueryMapper{
+		f.Table("test_3").WhereAny("tags", "go"),
+		f.Table("test_3").WhereContains("tags", []string{"go", "a b"}),
+		f.Table("test_3").WhereOverlaps("tags", []string{"a b", "sql"}),
+	}
+	for _, query := range queries {
+		result := arrayTest{}
+		if err := query.Get("code", "name", "tags").One(&result); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(record, result)
+	}
+
+	exists, err := f.Table("test_3").WhereContains("tags", []string{"go", "sql"}).Exists()
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.False(exists)
+}
+
+func Test_CopyFrom(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	require := require.New(t)
+
+	records := make(chan test1)
+	go func() {
+		defer close(records)
+		for i := 1; i <= 100; i++ {
+			records <- test1{Name: "copy", Total: float64(i)}
+		}
+	}()
+
+	result, err := f.CopyFrom(context.Background(), "test_1", []string{"name", "total"}, records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(int64(100), result.Rows)
+	require.Len(result.Errors, 0)
+
+	copied := []test1{}
+	if err := f.Table("test_1").Where("name", "=", "copy").Get("id").All(&copied); err != nil {
+		t.Fatal(err)
+	}
+	require.Len(copied, 100)
+
+	_, err = f.CopyFrom(context.Background(), "test_2", []string{"test_id"}, []test2{{TestID: -1}})
+	require.True(errors.Is(err, fluent.ErrForeignKeyViolation))
+}
+
+func Test_Transaction(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	t.Run("Rollback an insert in table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		tx, err := f.Begin()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := tx.Table("test_1").Insert(test1{Name: "tx_rollback"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		require.NoError(tx.Rollback())
+
+		record := test1{}
+		err = f.Table("test_1").Where("id", "=", id).Get("id").One(&record)
+		require.Equal(fluent.ErrNoRows, err)
+	})
+
+	t.Run("Commit an insert in table test 1", func(t *testing.T) {
+		require := require.New(t)
+
+		var id int
+		err := f.Transaction(context.Background(), func(tx fluent.Mapper) error {
+			id, err = tx.Table("test_1").Insert(test1{Name: "tx_commit"})
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		record := test1{}
+		if err := f.Table("test_1").Where("id", "=", id).Get("id", "name").One(&record); err != nil {
+			t.Fatal(err)
+		}
+		require.Equal(id, record.ID)
+		require.Equal("tx_commit", record.Name)
+	})
+
+	t.Run("Rollback a transaction after an error or panic", func(t *testing.T) {
+		require := require.New(t)
+
+		fail := errors.New("fail")
+		err := f.Transaction(context.Background(), func(tx fluent.Mapper) error {
+			if _, err := tx.Table("test_1").Insert(test1{Name: "tx_error"}); err != nil {
+				return err
+			}
+			return fail
+		})
+		require.Equal(fail, err)
+
+		err = f.Transaction(context.Background(), func(tx fluent.Mapper) error {
+			if _, err := tx.Table("test_1").Insert(test1{Name: "tx_error"}); err != nil {
+				return err
+			}
+			panic("fail")
+		})
+		require.Error(err)
+
+		records := []test1{}
+		if err := f.Table("test_1").Where("name", "=", "tx_error").Get("id").All(&records); err != nil {
+			t.Fatal(err)
+		}
+		require.Len(records, 0)
+	})
+}
+
+func Test_NestedTransaction(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	require := require.New(t)
+
+	fail := errors.New("fail")
+	err = f.Transaction(context.Background(), func(tx fluent.Mapper) error {
+		if _, err := tx.Table("test_1").Insert(test1{Name: "tx_outer"}); err != nil {
+			return err
+		}
+
+		err := tx.Transaction(context.Background(), func(tx fluent.Mapper) error {
+			if _, err := tx.Table("test_1").Insert(test1{Name: "tx_inner"}); err != nil {
+				return err
+			}
+			return fail
+		})
+		require.Equal(fail, err)
+
+		return tx.Transaction(context.Background(), func(tx fluent.Mapper) error {
+			_, err := tx.Table("test_1").Insert(test1{Name: "tx_inner_commit"})
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]int{"tx_outer": 1, "tx_inner": 0, "tx_inner_commit": 1} {
+		records := []test1{}
+		if err := f.Table("test_1").Where("name", "=", name).Get("id").All(&records); err != nil {
+			t.Fatal(err)
+		}
+		require.Len(records, expected)
+	}
+}
+
+func Test_RetryTransaction(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	require := require.New(t)
+
+	policy := fluent.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     fluent.ExponentialBackoff(10*time.Millisecond, 100*time.Millisecond),
+		TxOptions:   &sql.TxOptions{Isolation: sql.LevelSerializable},
+	}
+
+	var attempts int
+	err = f.RetryTransaction(context.Background(), policy, func(tx fluent.Mapper) error {
+		attempts++
+		if _, err := tx.Table("test_1").Insert(test1{Name: "tx_retry"}); err != nil {
+			return err
+		}
+
+		if attempts == 1 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	require.Equal(2, attempts)
+
+	records := []test1{}
+	if err := f.Table("test_1").Where("name", "=", "tx_retry").Get("id").All(&records); err != nil {
+		t.Fatal(err)
+	}
+	require.Len(records, 1)
+
+	attempts = 0
+	err = f.RetryTransaction(context.Background(), policy, func(tx fluent.Mapper) error {
+		attempts++
+		return &pq.Error{Code: "40P01"}
+	})
+	require.Error(err)
+	require.Equal(3, attempts)
 }
 
 func Test_Concurrency(t *testing.T) {
@@ -226,3 +995,43 @@ func Test_Concurrency(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+func Test_StmtCache(t *testing.T) {
+	f, err := connect()
+	if err != nil {
+		t.Fatalf("Unable to connect to the database: %s", err)
+	}
+
+	require := require.New(t)
+
+	f.StmtCache(10)
+	defer f.StmtCache(0)
+
+	for i := 1; i <= 3; i++ {
+		record := test1{}
+		err := f.Table("test_1").Where("id", "=", i).Get("id", "name").One(&record)
+		require.Nil(err)
+		require.Equal(i, record.ID)
+	}
+	require.Equal(uint64(2), f.StmtCacheStats().Hits)
+	require.Equal(uint64(1), f.StmtCacheStats().Misses)
+
+	db := f.GetDB()
+	_, err = db.Exec("CREATE TABLE stmt_cache (id SERIAL PRIMARY KEY)")
+	require.Nil(err)
+	defer db.Exec("DROP TABLE stmt_cache")
+
+	_, err = db.Exec("INSERT INTO stmt_cache DEFAULT VALUES")
+	require.Nil(err)
+
+	rows := []map[string]interface{}{}
+	require.Nil(f.Table("stmt_cache").Get("*").All(&rows))
+
+	// The cached plan of the statement is no longer valid
+	_, err = db.Exec("ALTER TABLE stmt_cache ADD COLUMN name VARCHAR(255) DEFAULT 'cached'")
+	require.Nil(err)
+
+	rows = []map[string]interface{}{}
+	require.Nil(f.Table("stmt_cache").Get("*").All(&rows))
+	require.Equal("cached", rows[0]["name"])
+}
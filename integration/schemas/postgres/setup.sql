@@ -24,4 +24,14 @@ CREATE TABLE test_2(
   deleted_at TIMESTAMP WITH TIME ZONE,
   PRIMARY KEY (id),
   FOREIGN KEY (test_id) REFERENCES test_1 (id)
-);
\ No newline at end of file
+);
+-- Test 3 table
+DROP TABLE IF EXISTS test_3;
+CREATE TABLE test_3(
+  code VARCHAR(32) DEFAULT md5(random()::text),
+  name VARCHAR(255),
+  settings JSONB,
+  tags TEXT[],
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+  PRIMARY KEY (code)
+);
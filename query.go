@@ -3,40 +3,127 @@ package fluent
 import (
 	"fmt"
 	"log"
+	"reflect"
 	"strings"
 	"sync"
 )
 
 const (
-	whereClause        = "WHERE"
-	andClause          = "AND"
-	orClause           = "OR"
-	isNullClause       = "IS NULL"
-	isNotNullClause    = "IS NOT NULL"
-	selectStatement    = "SELECT %s FROM %s"
-	insertStatement    = "INSERT INTO %s (%s) VALUES (%s) RETURNING id"
-	updateStatement    = "UPDATE %s SET"
-	joinStatement      = " INNER JOIN %s ON %s = %s"
-	leftJoinStatement  = " LEFT JOIN %s ON %s = %s"
-	whereStatement     = " %s %s %s $%d"
-	whereNullStatement = " %s %s %s"
-	groupByStatement   = " GROUP BY %s"
-	orderByStatement   = " ORDER BY %s"
-	limitStatement     = " LIMIT $%d"
-	offsetStatement    = " OFFSET $%d"
+	whereClause              = "WHERE"
+	andClause                = "AND"
+	orClause                 = "OR"
+	isNullClause             = "IS NULL"
+	isNotNullClause          = "IS NOT NULL"
+	inClause                 = "IN"
+	notInClause              = "NOT IN"
+	betweenClause            = "BETWEEN"
+	likeClause               = "LIKE"
+	iLikeClause              = "ILIKE"
+	trueClause               = "TRUE"
+	falseClause              = "FALSE"
+	jsonClause               = "->"
+	jsonTextClause           = "->>"
+	containsClause           = "@>"
+	hasKeyClause             = "?"
+	overlapClause            = "&&"
+	selectStatement          = "SELECT %s FROM %s"
+	existsStatement          = "SELECT EXISTS(%s)"
+	groupedCountStatement    = "SELECT COUNT(*) FROM (%s) AS grouped"
+	aggregateStatement       = "%s(%s)"
+	countClause              = "COUNT"
+	sumClause                = "SUM"
+	avgClause                = "AVG"
+	minClause                = "MIN"
+	maxClause                = "MAX"
+	insertStatement          = "INSERT INTO %s (%s) VALUES (%s)"
+	insertManyStatement      = "INSERT INTO %s (%s) VALUES %s"
+	valuesStatement          = "(%s)"
+	defaultClause            = "DEFAULT"
+	upsertReturningStatement = " RETURNING %s, (xmax = 0)"
+	onConflictStatement      = " ON CONFLICT"
+	conflictTargetStatement  = " (%s)"
+	onConstraintStatement    = " ON CONSTRAINT %s"
+	doNothingStatement       = " DO NOTHING"
+	doUpdateStatement        = " DO UPDATE SET %s"
+	excludedStatement        = "%s = EXCLUDED.%s"
+	setStatement             = "%s = %s"
+	updateStatement          = "UPDATE %s SET"
+	deleteStatement          = "DELETE FROM %s"
+	returningStatement       = " RETURNING %s"
+	joinStatement            = " INNER JOIN %s ON %s = %s"
+	leftJoinStatement        = " LEFT JOIN %s ON %s = %s"
+	whereStatement           = " %s %s"
+	whereValueStatement      = "%s %s $%d"
+	whereNullStatement       = "%s %s"
+	whereGroupStatement      = "(%s)"
+	whereInStatement         = "%s %s (%s)"
+	betweenStatement         = "%s %s $%d AND $%d"
+	jsonPathStatement        = "%s %s $%d %s $%d"
+	anyStatement             = "$%d = ANY(%s)"
+	groupByStatement         = " GROUP BY %s"
+	orderByStatement         = " ORDER BY %s"
+	limitStatement           = " LIMIT $%d"
+	offsetStatement          = " OFFSET $%d"
+	defaultPrimaryKey        = "id"
+
+	// maxParameters is the maximum number of
+	// parameters Postgres accepts in a single query
+	maxParameters = 65535
 )
 
+// defaultValue is inserted as DEFAULT
+type defaultValue struct{}
+
+type conditionType int
+
+const (
+	whereValue conditionType = iota
+	whereNull
+	whereGroup
+	whereIn
+	whereBetween
+	whereJSONPath
+	whereAny
+)
+
+// condition is a single where clause, a group
+// holds the conditions between the parentheses
+type condition struct {
+	kind        conditionType
+	conjunction string
+	column      string
+	// path is the JSON operator applied to the column
+	path     string
+	operator string
+	args     []interface{}
+	group    []condition
+}
+
+// conflict holds the ON CONFLICT clause of an upsert
+type conflict struct {
+	target     []string
+	constraint string
+	doNothing  bool
+	update     []string
+	set        [][]string
+}
+
 type query struct {
 	stmt             string
 	columns          []string
 	table            string
+	primaryKey       string
 	join, leftJoin   [][]interface{}
-	where, whereNull [][]interface{}
+	where            []condition
 	orderBy, groupBy []string
 	limit, offset    int
+	conflict         conflict
 	args             []interface{}
 	argCounter       int
+	unfiltered       bool
 	debug            bool
+	strict           bool
+	err              error
 	mutex            *sync.RWMutex
 }
 
@@ -53,6 +140,20 @@ func (q *query) log() {
 	}
 }
 
+func (q *query) isDebug() bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.debug
+}
+
+func (q *query) isStrict() bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.strict
+}
+
 type queryOption func(q *query)
 
 func (q *query) builder(options ...queryOption) {
@@ -65,24 +166,127 @@ func (q *query) builder(options ...queryOption) {
 }
 
 func buildInsert(cols []string, args []interface{}) queryOption {
+	return func(q *query) {
+		q.buildValues(cols, args)
+		q.stmt += fmt.Sprintf(returningStatement, q.pk())
+	}
+}
+
+// buildInsertReturning returns all the columns of the inserted record
+func buildInsertReturning(cols []string, args []interface{}) queryOption {
+	return func(q *query) {
+		q.buildValues(cols, args)
+		q.stmt += fmt.Sprintf(returningStatement, "*")
+	}
+}
+
+func buildInsertMany(cols []string, rows [][]interface{}) queryOption {
 	return func(q *query) {
 		q.columns = cols
-		q.args = args
 
-		vals := []string{}
-		for i := 1; i <= len(q.args); i++ {
-			vals = append(vals, fmt.Sprintf("$%d", i))
+		values := []string{}
+		for _, row := range rows {
+			vals := []string{}
+			for _, arg := range row {
+				if _, ok := arg.(defaultValue); ok {
+					vals = append(vals, defaultClause)
+					continue
+				}
+
+				q.args = append(q.args, arg)
+				vals = append(vals, fmt.Sprintf("$%d", q.argCounter))
+				q.argCounter++
+			}
+			values = append(values, fmt.Sprintf(valuesStatement, strings.Join(vals, ",")))
 		}
 
-		q.stmt = fmt.Sprintf(insertStatement, q.table, strings.Join(q.columns, ","), strings.Join(vals, ","))
+		q.stmt = fmt.Sprintf(insertManyStatement, q.table, strings.Join(q.columns, ","), strings.Join(values, ","))
+		q.stmt += fmt.Sprintf(returningStatement, q.pk())
 	}
 }
 
+func buildUpsert(cols []string, args []interface{}) queryOption {
+	return func(q *query) {
+		q.buildValues(cols, args)
+		q.stmt += onConflictStatement
+
+		if q.conflict.constraint != "" {
+			q.stmt += fmt.Sprintf(onConstraintStatement, q.conflict.constraint)
+		} else if len(q.conflict.target) > 0 {
+			q.stmt += fmt.Sprintf(conflictTargetStatement, strings.Join(q.conflict.target, ","))
+		}
+
+		if q.conflict.doNothing {
+			q.stmt += doNothingStatement
+		} else {
+			// Postgres only infers the conflict for DO NOTHING
+			if q.conflict.constraint == "" && len(q.conflict.target) == 0 {
+				q.err = ErrNoConflictTarget
+			}
+
+			q.stmt += fmt.Sprintf(doUpdateStatement, strings.Join(q.conflictSet(), ", "))
+		}
+
+		q.stmt += fmt.Sprintf(upsertReturningStatement, q.pk())
+	}
+}
+
+// conflictSet returns the SET expressions of the DO UPDATE clause, when no columns
+// are provided all the inserted columns except the conflict target are updated
+func (q *query) conflictSet() []string {
+	update := q.conflict.update
+	if len(update) == 0 && len(q.conflict.set) == 0 {
+		for _, col := range q.columns {
+			if !contains(q.conflict.target, col) {
+				update = append(update, col)
+			}
+		}
+		if len(update) == 0 {
+			update = q.columns
+		}
+	}
+
+	set := []string{}
+	for _, col := range update {
+		set = append(set, fmt.Sprintf(excludedStatement, col, col))
+	}
+	for _, s := range q.conflict.set {
+		set = append(set, fmt.Sprintf(setStatement, s[0], s[1]))
+	}
+
+	return set
+}
+
+// pk returns the primary key column, id by default
+func (q *query) pk() string {
+	if q.primaryKey == "" {
+		return defaultPrimaryKey
+	}
+	return q.primaryKey
+}
+
+func (q *query) buildValues(cols []string, args []interface{}) {
+	q.columns = cols
+	q.args = args
+
+	vals := []string{}
+	for i := 1; i <= len(q.args); i++ {
+		vals = append(vals, fmt.Sprintf("$%d", i))
+	}
+
+	q.stmt = fmt.Sprintf(insertStatement, q.table, strings.Join(q.columns, ","), strings.Join(vals, ","))
+}
+
 func buildUpdate(cols []string, args []interface{}) queryOption {
 	return func(q *query) {
 		q.columns = cols
 		q.args = args
 
+		if len(q.columns) == 0 {
+			q.err = ErrNoColumns
+			return
+		}
+
 		stmt := fmt.Sprintf(updateStatement, q.table)
 		for _, col := range q.columns {
 			stmt += fmt.Sprintf(" %s = $%d,", col, q.argCounter)
@@ -93,69 +297,141 @@ func buildUpdate(cols []string, args []interface{}) queryOption {
 	}
 }
 
-func buildSelect() queryOption {
+func buildDelete() queryOption {
 	return func(q *query) {
-		q.stmt = fmt.Sprintf(selectStatement, strings.Join(q.columns, ","), q.table)
+		if len(q.where) == 0 && !q.unfiltered {
+			q.err = ErrUnfilteredDelete
+		}
+
+		q.stmt = fmt.Sprintf(deleteStatement, q.table)
 	}
 }
 
-func buildWhere() queryOption {
+// buildReturning returns the columns, or all columns when none are set
+func buildReturning() queryOption {
 	return func(q *query) {
-		if len(q.where) == 0 {
-			return
+		columns := "*"
+		if len(q.columns) > 0 {
+			columns = strings.Join(q.columns, ",")
 		}
+		q.stmt += fmt.Sprintf(returningStatement, columns)
+	}
+}
+
+func buildSelect() queryOption {
+	return func(q *query) {
+		q.stmt = fmt.Sprintf(selectStatement, strings.Join(q.columns, ","), q.table)
+	}
+}
 
-		var stmtType string
-		for _, where := range q.where {
-			if len(where) != 3 {
-				continue
+// buildAggregate builds the select of the aggregate function over the column,
+// a grouped count is wrapped to count the groups
+func buildAggregate(function, column string) queryOption {
+	return func(q *query) {
+		if len(q.groupBy) > 0 {
+			if function != countClause {
+				q.err = ErrGroupedAggregate
 			}
 
-			column := where[0].(string)
-			operator := where[1].(string)
-			arg := where[2]
+			q.columns = []string{"1"}
+			q.buildFilteredSelect()
+			q.stmt = fmt.Sprintf(groupedCountStatement, q.stmt)
+			return
+		}
 
-			q.args = append(q.args, arg)
+		q.columns = []string{fmt.Sprintf(aggregateStatement, function, column)}
+		q.buildFilteredSelect()
+	}
+}
 
-			stmtType = whereClause
-			if strings.Contains(q.stmt, whereClause) {
-				stmtType = andClause
-			}
+// buildExists builds the select wrapped in SELECT EXISTS
+func buildExists() queryOption {
+	return func(q *query) {
+		q.columns = []string{"1"}
+		q.buildFilteredSelect()
+		q.stmt = fmt.Sprintf(existsStatement, q.stmt)
+	}
+}
 
-			q.stmt += fmt.Sprintf(whereStatement, stmtType, column, operator, q.argCounter)
-			q.argCounter++
-		}
+// buildFilteredSelect builds the select with the joins, where and group by clauses
+func (q *query) buildFilteredSelect() {
+	for _, option := range []queryOption{
+		buildSelect(),
+		buildJoin(),
+		buildLeftJoin(),
+		buildWhere(),
+		buildGroupBy(),
+	} {
+		option(q)
 	}
 }
 
-func buildWhereNull() queryOption {
+func buildWhere() queryOption {
 	return func(q *query) {
-		if len(q.whereNull) == 0 {
+		if len(q.where) == 0 {
 			return
 		}
 
-		var stmtType string
-		for _, where := range q.whereNull {
-			if len(where) != 2 {
-				continue
-			}
+		q.stmt += fmt.Sprintf(whereStatement, whereClause, q.buildConditions(q.where))
+	}
+}
 
-			col := where[0].(string)
-			isNull := where[1].(bool)
+// buildConditions joins the conditions with their conjunction,
+// the conjunction of the first condition is ignored
+func (q *query) buildConditions(conditions []condition) string {
+	var stmt string
+	for i, c := range conditions {
+		if i > 0 {
+			stmt += fmt.Sprintf(" %s ", c.conjunction)
+		}
 
-			stmtType = whereClause
-			if strings.Contains(q.stmt, whereClause) {
-				stmtType = andClause
-			}
+		switch c.kind {
+		case whereNull:
+			stmt += fmt.Sprintf(whereNullStatement, c.column, c.operator)
+		case whereGroup:
+			stmt += fmt.Sprintf(whereGroupStatement, q.buildConditions(c.group))
+		case whereIn:
+			stmt += q.buildIn(c)
+		case whereBetween:
+			q.args = append(q.args, c.args...)
+			stmt += fmt.Sprintf(betweenStatement, c.column, c.operator, q.argCounter, q.argCounter+1)
+			q.argCounter += 2
+		case whereJSONPath:
+			q.args = append(q.args, c.args...)
+			stmt += fmt.Sprintf(jsonPathStatement, c.column, c.path, q.argCounter, c.operator, q.argCounter+1)
+			q.argCounter += 2
+		case whereAny:
+			q.args = append(q.args, c.args...)
+			stmt += fmt.Sprintf(anyStatement, q.argCounter, c.column)
+			q.argCounter++
+		default:
+			q.args = append(q.args, c.args...)
+			stmt += fmt.Sprintf(whereValueStatement, c.column, c.operator, q.argCounter)
+			q.argCounter++
+		}
+	}
 
-			var nullStmt = isNotNullClause
-			if isNull {
-				nullStmt = isNullClause
-			}
+	return stmt
+}
 
-			q.stmt += fmt.Sprintf(whereNullStatement, stmtType, col, nullStmt)
+// buildIn expands the values into a placeholder list, an empty
+// list never matches for IN and always matches for NOT IN
+func (q *query) buildIn(c condition) string {
+	if len(c.args) == 0 {
+		if c.operator == notInClause {
+			return trueClause
 		}
+		return falseClause
 	}
+
+	vals := []string{}
+	for _, arg := range c.args {
+		q.args = append(q.args, arg)
+		vals = append(vals, fmt.Sprintf("$%d", q.argCounter))
+		q.argCounter++
+	}
+
+	return fmt.Sprintf(whereInStatement, c.column, c.operator, strings.Join(vals, ","))
 }
 
 func buildJoin() queryOption {
@@ -218,12 +494,30 @@ func setDebug(s bool) queryOption {
 	}
 }
 
+func setStrict(s bool) queryOption {
+	return func(q *query) {
+		q.strict = s
+	}
+}
+
+func setUnfiltered(u bool) queryOption {
+	return func(q *query) {
+		q.unfiltered = u
+	}
+}
+
 func setTable(t string) queryOption {
 	return func(q *query) {
 		q.table = t
 	}
 }
 
+func setPrimaryKey(pk string) queryOption {
+	return func(q *query) {
+		q.primaryKey = pk
+	}
+}
+
 func setColumns(c []string) queryOption {
 	return func(q *query) {
 		q.columns = c
@@ -231,14 +525,182 @@ func setColumns(c []string) queryOption {
 }
 
 func setWhere(w []interface{}) queryOption {
+	return setCondition(andClause, w)
+}
+
+func setOrWhere(w []interface{}) queryOption {
+	return setCondition(orClause, w)
+}
+
+func setWhereNull(wn []interface{}) queryOption {
+	return setNullCondition(andClause, wn)
+}
+
+func setOrWhereNull(wn []interface{}) queryOption {
+	return setNullCondition(orClause, wn)
+}
+
+func setWhereIn(w []interface{}) queryOption {
 	return func(q *query) {
-		q.where = append(q.where, w)
+		if len(w) != 3 {
+			return
+		}
+
+		q.where = append(q.where, condition{
+			kind:        whereIn,
+			conjunction: andClause,
+			column:      w[0].(string),
+			operator:    w[1].(string),
+			args:        toSlice(w[2]),
+		})
 	}
 }
 
-func setWhereNull(wn []interface{}) queryOption {
+// setWhereJSON set the condition on the key of a JSON column,
+// the path is either the -> or the ->> operator
+func setWhereJSON(w []interface{}) queryOption {
+	return func(q *query) {
+		if len(w) != 5 {
+			return
+		}
+
+		q.where = append(q.where, condition{
+			kind:        whereJSONPath,
+			conjunction: andClause,
+			column:      w[0].(string),
+			path:        w[1].(string),
+			operator:    w[3].(string),
+			args:        []interface{}{w[2], w[4]},
+		})
+	}
+}
+
+// setWhereAny set the condition that the value is an element of the array column
+func setWhereAny(w []interface{}) queryOption {
+	return func(q *query) {
+		if len(w) != 2 {
+			return
+		}
+
+		q.where = append(q.where, condition{
+			kind:        whereAny,
+			conjunction: andClause,
+			column:      w[0].(string),
+			args:        []interface{}{w[1]},
+		})
+	}
+}
+
+func setError(err error) queryOption {
+	return func(q *query) {
+		if q.err == nil {
+			q.err = err
+		}
+	}
+}
+
+func setWhereBetween(w []interface{}) queryOption {
+	return func(q *query) {
+		if len(w) != 3 {
+			return
+		}
+
+		q.where = append(q.where, condition{
+			kind:        whereBetween,
+			conjunction: andClause,
+			column:      w[0].(string),
+			operator:    betweenClause,
+			args:        []interface{}{w[1], w[2]},
+		})
+	}
+}
+
+func setWhereGroup(g []condition) queryOption {
+	return setGroupCondition(andClause, g)
+}
+
+func setOrWhereGroup(g []condition) queryOption {
+	return setGroupCondition(orClause, g)
+}
+
+func setCondition(conjunction string, w []interface{}) queryOption {
 	return func(q *query) {
-		q.whereNull = append(q.whereNull, wn)
+		if len(w) != 3 {
+			return
+		}
+
+		q.where = append(q.where, condition{
+			kind:        whereValue,
+			conjunction: conjunction,
+			column:      w[0].(string),
+			operator:    w[1].(string),
+			args:        []interface{}{w[2]},
+		})
+	}
+}
+
+func setNullCondition(conjunction string, wn []interface{}) queryOption {
+	return func(q *query) {
+		if len(wn) != 2 {
+			return
+		}
+
+		var operator = isNotNullClause
+		if wn[1].(bool) {
+			operator = isNullClause
+		}
+
+		q.where = append(q.where, condition{
+			kind:        whereNull,
+			conjunction: conjunction,
+			column:      wn[0].(string),
+			operator:    operator,
+		})
+	}
+}
+
+func setGroupCondition(conjunction string, g []condition) queryOption {
+	return func(q *query) {
+		// Skip empty groups, they would render as ()
+		if len(g) == 0 {
+			return
+		}
+
+		q.where = append(q.where, condition{
+			kind:        whereGroup,
+			conjunction: conjunction,
+			group:       g,
+		})
+	}
+}
+
+func setConflictTarget(t []string) queryOption {
+	return func(q *query) {
+		q.conflict.target = t
+	}
+}
+
+func setOnConstraint(c string) queryOption {
+	return func(q *query) {
+		q.conflict.constraint = c
+	}
+}
+
+func setDoNothing() queryOption {
+	return func(q *query) {
+		q.conflict.doNothing = true
+	}
+}
+
+func setDoUpdate(cols []string) queryOption {
+	return func(q *query) {
+		q.conflict.update = append(q.conflict.update, cols...)
+	}
+}
+
+func setDoUpdateSet(col, expr string) queryOption {
+	return func(q *query) {
+		q.conflict.set = append(q.conflict.set, []string{col, expr})
 	}
 }
 
@@ -277,3 +739,39 @@ func setOffset(o int) queryOption {
 		q.offset = o
 	}
 }
+
+// toSlice converts a slice or array to a slice of interfaces,
+// any other value is returned as a single element
+func toSlice(v interface{}) []interface{} {
+	valOf := reflect.ValueOf(v)
+	switch valOf.Kind() {
+	case reflect.Slice, reflect.Array:
+		// Byte slices are a single value
+		if valOf.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+
+		s := make([]interface{}, valOf.Len())
+		for i := range s {
+			s[i] = valOf.Index(i).Interface()
+		}
+		return s
+	case reflect.Invalid:
+		return nil
+	}
+
+	return []interface{}{v}
+}
+
+func contains(s []string, v string) bool {
+	return indexOf(s, v) >= 0
+}
+
+func indexOf(s []string, v string) int {
+	for i, j := range s {
+		if j == v {
+			return i
+		}
+	}
+	return -1
+}
@@ -5,6 +5,7 @@ import (
 
 	"time"
 
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 )
 
@@ -98,10 +99,258 @@ func Test_WhereNull(t *testing.T) {
 			f.WhereNull(where[0].(string), where[1].(bool))
 		}
 
-		require.Equal(len(tc.whereNull), len(f.query.whereNull))
+		require.Equal(len(tc.whereNull), len(f.query.where))
 
-		f.query.builder(buildWhereNull())
+		f.query.builder(buildWhere())
+		require.Equal(tc.expectedStmt, f.query.stmt)
+	}
+}
+
+func Test_OrWhere(t *testing.T) {
+	require := require.New(t)
+
+	f := &Fluent{}
+
+	tests := []struct {
+		build              func(q QueryMapper)
+		expectedArgs       []interface{}
+		expectedArgCounter int
+		expectedStmt       string
+	}{
+		{
+			build: func(q QueryMapper) {
+				q.Where("id", "=", 1).OrWhere("name", "=", "gerald")
+			},
+			expectedArgs:       []interface{}{1, "gerald"},
+			expectedArgCounter: 3,
+			expectedStmt:       " WHERE id = $1 OR name = $2",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.WhereNull("deleted_at", true).OrWhere("id", "=", 1).OrWhereNull("updated_at", false)
+			},
+			expectedArgs:       []interface{}{1},
+			expectedArgCounter: 2,
+			expectedStmt:       " WHERE deleted_at IS NULL OR id = $1 OR updated_at IS NOT NULL",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.WhereGroup(func(g QueryMapper) {
+					g.Where("status", "=", "open").OrWhere("owner", "=", 7)
+				}).WhereNull("deleted_at", true)
+			},
+			expectedArgs:       []interface{}{"open", 7},
+			expectedArgCounter: 3,
+			expectedStmt:       " WHERE (status = $1 OR owner = $2) AND deleted_at IS NULL",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.Where("id", ">", 5).OrWhereGroup(func(g QueryMapper) {
+					g.Where("total", ">", 12.00).WhereGroup(func(g QueryMapper) {
+						g.Where("name", "=", "gerald").OrWhere("name", "=", "henry")
+					})
+				})
+			},
+			expectedArgs:       []interface{}{5, 12.00, "gerald", "henry"},
+			expectedArgCounter: 5,
+			expectedStmt:       " WHERE id > $1 OR (total > $2 AND (name = $3 OR name = $4))",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.WhereGroup(func(g QueryMapper) {}).Where("id", "=", 1)
+			},
+			expectedArgs:       []interface{}{1},
+			expectedArgCounter: 2,
+			expectedStmt:       " WHERE id = $1",
+		},
+	}
+
+	for _, tc := range tests {
+		f.query = newQuery()
+		tc.build(f)
+
+		f.query.builder(buildWhere())
 		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedArgs, f.query.args)
+		require.Equal(tc.expectedArgCounter, f.query.argCounter)
+	}
+}
+
+func Test_WhereIn(t *testing.T) {
+	require := require.New(t)
+
+	f := &Fluent{}
+	from, to := time.Now().Add(-time.Hour), time.Now()
+
+	tests := []struct {
+		build              func(q QueryMapper)
+		expectedArgs       []interface{}
+		expectedArgCounter int
+		expectedStmt       string
+	}{
+		{
+			build: func(q QueryMapper) {
+				q.WhereIn("id", []int{1, 2, 3})
+			},
+			expectedArgs:       []interface{}{1, 2, 3},
+			expectedArgCounter: 4,
+			expectedStmt:       " WHERE id IN ($1,$2,$3)",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.Where("total", ">", 12.00).WhereNotIn("name", []string{"gerald", "henry"})
+			},
+			expectedArgs:       []interface{}{12.00, "gerald", "henry"},
+			expectedArgCounter: 4,
+			expectedStmt:       " WHERE total > $1 AND name NOT IN ($2,$3)",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.WhereIn("id", 7)
+			},
+			expectedArgs:       []interface{}{7},
+			expectedArgCounter: 2,
+			expectedStmt:       " WHERE id IN ($1)",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.WhereIn("id", []int{}).WhereNotIn("name", nil)
+			},
+			expectedArgCounter: 1,
+			expectedStmt:       " WHERE FALSE AND TRUE",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.WhereBetween("created_at", from, to).WhereIn("id", [2]int64{4, 5})
+			},
+			expectedArgs:       []interface{}{from, to, int64(4), int64(5)},
+			expectedArgCounter: 5,
+			expectedStmt:       " WHERE created_at BETWEEN $1 AND $2 AND id IN ($3,$4)",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.WhereLike("name", "ger%").WhereILike("name", "%ALD")
+			},
+			expectedArgs:       []interface{}{"ger%", "%ALD"},
+			expectedArgCounter: 3,
+			expectedStmt:       " WHERE name LIKE $1 AND name ILIKE $2",
+		},
+	}
+
+	for _, tc := range tests {
+		f.query = newQuery()
+		tc.build(f)
+
+		f.query.builder(buildWhere())
+		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedArgs, f.query.args)
+		require.Equal(tc.expectedArgCounter, f.query.argCounter)
+	}
+}
+
+func Test_WhereJSON(t *testing.T) {
+	require := require.New(t)
+
+	f := &Fluent{}
+
+	tests := []struct {
+		build              func(q QueryMapper)
+		expectedArgs       []interface{}
+		expectedArgCounter int
+		expectedStmt       string
+	}{
+		{
+			build: func(q QueryMapper) {
+				q.WhereJSON("settings", "theme", "=", "dark")
+			},
+			expectedArgs:       []interface{}{"theme", `"dark"`},
+			expectedArgCounter: 3,
+			expectedStmt:       " WHERE settings -> $1 = $2",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.Where("id", "=", 1).
+					WhereJSONText("settings", "theme", "=", "dark").
+					WhereJSONContains("settings", map[string]bool{"beta": true}).
+					WhereJSONHasKey("settings", "theme")
+			},
+			expectedArgs:       []interface{}{1, "theme", "dark", `{"beta":true}`, "theme"},
+			expectedArgCounter: 6,
+			expectedStmt:       " WHERE id = $1 AND settings ->> $2 = $3 AND settings @> $4 AND settings ? $5",
+		},
+	}
+
+	for _, tc := range tests {
+		f.query = newQuery()
+		tc.build(f)
+
+		f.query.builder(buildWhere())
+		require.Nil(f.query.err)
+		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedArgs, f.query.args)
+		require.Equal(tc.expectedArgCounter, f.query.argCounter)
+	}
+
+	f.query = newQuery()
+	f.WhereJSONContains("settings", func() {})
+	require.NotNil(f.query.err)
+
+	// The error of a group isn't dropped with its condition
+	f.query = newQuery()
+	f.Where("owner", "=", 1).OrWhereGroup(func(q QueryMapper) {
+		q.WhereGroup(func(q QueryMapper) {
+			q.WhereJSON("settings", "theme", "=", make(chan int))
+		})
+	})
+	require.NotNil(f.query.err)
+}
+
+func Test_WhereArray(t *testing.T) {
+	require := require.New(t)
+
+	f := &Fluent{}
+
+	tests := []struct {
+		build              func(q QueryMapper)
+		expectedArgs       []interface{}
+		expectedArgCounter int
+		expectedStmt       string
+	}{
+		{
+			build: func(q QueryMapper) {
+				q.WhereAny("tags", "go")
+			},
+			expectedArgs:       []interface{}{"go"},
+			expectedArgCounter: 2,
+			expectedStmt:       " WHERE $1 = ANY(tags)",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.Where("id", "=", 1).
+					WhereContains("tags", []string{"go", "sql"}).
+					WhereOverlaps("scores", []int64{1, 2}).
+					WhereAny("tags", "go")
+			},
+			expectedArgs: []interface{}{
+				1,
+				pq.Array([]string{"go", "sql"}),
+				pq.Array([]int64{1, 2}),
+				"go",
+			},
+			expectedArgCounter: 5,
+			expectedStmt:       " WHERE id = $1 AND tags @> $2 AND scores && $3 AND $4 = ANY(tags)",
+		},
+	}
+
+	for _, tc := range tests {
+		f.query = newQuery()
+		tc.build(f)
+
+		f.query.builder(buildWhere())
+		require.Nil(f.query.err)
+		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedArgs, f.query.args)
+		require.Equal(tc.expectedArgCounter, f.query.argCounter)
 	}
 }
 
@@ -268,7 +517,7 @@ func Test_BuildSelect(t *testing.T) {
 			orderBy:            []string{"id"},
 			offset:             0,
 			limit:              5,
-			expectedStmt:       "SELECT id,name,total,created_at,is_active FROM test GROUP BY name ORDER BY id WHERE id = $1 OFFSET $2 LIMIT $3",
+			expectedStmt:       "SELECT id,name,total,created_at,is_active FROM test WHERE id = $1 GROUP BY name ORDER BY id OFFSET $2 LIMIT $3",
 			expectedArgs:       []interface{}{1, 0, 5},
 			expectedArgCounter: 4,
 		},
@@ -280,7 +529,7 @@ func Test_BuildSelect(t *testing.T) {
 			orderBy:            []string{"total"},
 			offset:             5,
 			limit:              10,
-			expectedStmt:       "SELECT * FROM test GROUP BY is_active ORDER BY total WHERE total = $1 OFFSET $2 LIMIT $3",
+			expectedStmt:       "SELECT * FROM test WHERE total = $1 GROUP BY is_active ORDER BY total OFFSET $2 LIMIT $3",
 			expectedArgs:       []interface{}{12.00, 5, 10},
 			expectedArgCounter: 4,
 		},
@@ -325,6 +574,7 @@ func Test_Insert(t *testing.T) {
 
 	tests := []struct {
 		table        string
+		primaryKey   string
 		cols         []string
 		args         []interface{}
 		expectedStmt string
@@ -341,6 +591,13 @@ func Test_Insert(t *testing.T) {
 			args:         []interface{}{"gerald", 12.00, 1},
 			expectedStmt: "INSERT INTO test (name,total,is_active) VALUES ($1,$2,$3) RETURNING id",
 		},
+		{
+			table:        "test",
+			primaryKey:   "uuid",
+			cols:         []string{"name"},
+			args:         []interface{}{"gerald"},
+			expectedStmt: "INSERT INTO test (name) VALUES ($1) RETURNING uuid",
+		},
 	}
 
 	for _, tc := range tests {
@@ -348,11 +605,19 @@ func Test_Insert(t *testing.T) {
 
 		f.query.builder(
 			setTable(tc.table),
+			setPrimaryKey(tc.primaryKey),
 			buildInsert(tc.cols, tc.args),
 		)
 
 		require.Equal(tc.expectedStmt, f.query.stmt)
 	}
+
+	f.query = newQuery()
+	f.query.builder(
+		setTable("test"),
+		buildInsertReturning([]string{"name"}, []interface{}{"gerald"}),
+	)
+	require.Equal("INSERT INTO test (name) VALUES ($1) RETURNING *", f.query.stmt)
 }
 
 func Test_Update(t *testing.T) {
@@ -368,6 +633,7 @@ func Test_Update(t *testing.T) {
 		expectedStmt       string
 		expectedArgs       []interface{}
 		expectedArgCounter int
+		expectedErr        error
 	}{
 		{
 			table:              "test",
@@ -386,6 +652,10 @@ func Test_Update(t *testing.T) {
 			expectedStmt:       "UPDATE test SET name = $1, total = $2 WHERE id = $3",
 			expectedArgCounter: 3,
 		},
+		{
+			table:       "test",
+			expectedErr: ErrNoColumns,
+		},
 	}
 
 	for _, tc := range tests {
@@ -402,5 +672,276 @@ func Test_Update(t *testing.T) {
 		)
 
 		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedErr, f.query.err)
+	}
+}
+
+func Test_Delete(t *testing.T) {
+	require := require.New(t)
+
+	f := &Fluent{}
+
+	tests := []struct {
+		table        string
+		where        []interface{}
+		whereNull    []interface{}
+		unfiltered   bool
+		returning    []string
+		expectedStmt string
+		expectedArgs []interface{}
+		expectedErr  error
+	}{
+		{
+			table:        "test",
+			where:        []interface{}{"id", "=", 1},
+			expectedStmt: "DELETE FROM test WHERE id = $1",
+			expectedArgs: []interface{}{1},
+		},
+		{
+			table:        "test",
+			where:        []interface{}{"id", "=", 1},
+			whereNull:    []interface{}{"deleted_at", false},
+			returning:    []string{"id", "name"},
+			expectedStmt: "DELETE FROM test WHERE id = $1 AND deleted_at IS NOT NULL RETURNING id,name",
+			expectedArgs: []interface{}{1},
+		},
+		{
+			table:        "test",
+			where:        []interface{}{"id", "=", 1},
+			returning:    []string{},
+			expectedStmt: "DELETE FROM test WHERE id = $1 RETURNING *",
+			expectedArgs: []interface{}{1},
+		},
+		{
+			table:        "test",
+			expectedStmt: "DELETE FROM test",
+			expectedErr:  ErrUnfilteredDelete,
+		},
+		{
+			table:        "test",
+			unfiltered:   true,
+			expectedStmt: "DELETE FROM test",
+		},
+	}
+
+	for _, tc := range tests {
+		f.query = newQuery()
+
+		if tc.where != nil {
+			f.query.builder(setWhere(tc.where))
+		}
+		if tc.whereNull != nil {
+			f.query.builder(setWhereNull(tc.whereNull))
+		}
+
+		f.query.builder(
+			setTable(tc.table),
+			setUnfiltered(tc.unfiltered),
+		)
+
+		if tc.returning != nil {
+			f.DeleteReturning(tc.returning...)
+		} else {
+			f.query.builder(
+				buildDelete(),
+				buildWhere(),
+			)
+		}
+
+		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedArgs, f.query.args)
+		require.Equal(tc.expectedErr, f.query.err)
+	}
+}
+
+func Test_Upsert(t *testing.T) {
+	require := require.New(t)
+
+	f := &Fluent{}
+
+	tests := []struct {
+		build        func(q QueryMapper)
+		target       []string
+		cols         []string
+		args         []interface{}
+		expectedStmt string
+		expectedErr  error
+	}{
+		{
+			target:       []string{"id"},
+			cols:         []string{"id", "name", "total"},
+			args:         []interface{}{1, "gerald", 12.00},
+			expectedStmt: "INSERT INTO test (id,name,total) VALUES ($1,$2,$3) ON CONFLICT (id) DO UPDATE SET name = EXCLUDED.name, total = EXCLUDED.total RETURNING id, (xmax = 0)",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.DoNothing()
+			},
+			target:       []string{"name"},
+			cols:         []string{"name"},
+			args:         []interface{}{"gerald"},
+			expectedStmt: "INSERT INTO test (name) VALUES ($1) ON CONFLICT (name) DO NOTHING RETURNING id, (xmax = 0)",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.OnConstraint("test_name_key").DoUpdate("total").DoUpdateSet("updated_at", "NOW()")
+			},
+			cols:         []string{"name", "total"},
+			args:         []interface{}{"gerald", 12.00},
+			expectedStmt: "INSERT INTO test (name,total) VALUES ($1,$2) ON CONFLICT ON CONSTRAINT test_name_key DO UPDATE SET total = EXCLUDED.total, updated_at = NOW() RETURNING id, (xmax = 0)",
+		},
+		{
+			target:       []string{"id"},
+			cols:         []string{"id"},
+			args:         []interface{}{1},
+			expectedStmt: "INSERT INTO test (id) VALUES ($1) ON CONFLICT (id) DO UPDATE SET id = EXCLUDED.id RETURNING id, (xmax = 0)",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.DoNothing()
+			},
+			cols:         []string{"name"},
+			args:         []interface{}{"gerald"},
+			expectedStmt: "INSERT INTO test (name) VALUES ($1) ON CONFLICT DO NOTHING RETURNING id, (xmax = 0)",
+		},
+		{
+			cols:         []string{"name"},
+			args:         []interface{}{"gerald"},
+			expectedStmt: "INSERT INTO test (name) VALUES ($1) ON CONFLICT DO UPDATE SET name = EXCLUDED.name RETURNING id, (xmax = 0)",
+			expectedErr:  ErrNoConflictTarget,
+		},
 	}
+
+	for _, tc := range tests {
+		f.query = newQuery()
+
+		if tc.build != nil {
+			tc.build(f)
+		}
+
+		f.query.builder(
+			setTable("test"),
+			setConflictTarget(tc.target),
+			buildUpsert(tc.cols, tc.args),
+		)
+
+		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.args, f.query.args)
+		require.Equal(tc.expectedErr, f.query.err)
+	}
+}
+
+func Test_InsertMany(t *testing.T) {
+	require := require.New(t)
+
+	f := &Fluent{}
+
+	tests := []struct {
+		cols               []string
+		rows               [][]interface{}
+		expectedStmt       string
+		expectedArgs       []interface{}
+		expectedArgCounter int
+	}{
+		{
+			cols: []string{"name", "total"},
+			rows: [][]interface{}{
+				{"gerald", 12.00},
+				{"henry", 10.00},
+			},
+			expectedStmt:       "INSERT INTO test (name,total) VALUES ($1,$2),($3,$4) RETURNING id",
+			expectedArgs:       []interface{}{"gerald", 12.00, "henry", 10.00},
+			expectedArgCounter: 5,
+		},
+		{
+			cols: []string{"name", "total"},
+			rows: [][]interface{}{
+				{"gerald", defaultValue{}},
+				{defaultValue{}, 10.00},
+			},
+			expectedStmt:       "INSERT INTO test (name,total) VALUES ($1,DEFAULT),(DEFAULT,$2) RETURNING id",
+			expectedArgs:       []interface{}{"gerald", 10.00},
+			expectedArgCounter: 3,
+		},
+	}
+
+	for _, tc := range tests {
+		f.query = newQuery()
+
+		f.query.builder(
+			setTable("test"),
+			buildInsertMany(tc.cols, tc.rows),
+		)
+
+		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedArgs, f.query.args)
+		require.Equal(tc.expectedArgCounter, f.query.argCounter)
+	}
+}
+
+func Test_Aggregate(t *testing.T) {
+	require := require.New(t)
+
+	f := &Fluent{}
+
+	tests := []struct {
+		build        func(q QueryMapper)
+		option       queryOption
+		expectedStmt string
+		expectedArgs []interface{}
+		expectedErr  error
+	}{
+		{
+			option:       buildAggregate(countClause, "*"),
+			expectedStmt: "SELECT COUNT(*) FROM test",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.Join("test_2", "test.id", "test_2.test_id").Where("is_active", "=", 1).GroupBy("test.id")
+			},
+			option:       buildAggregate(countClause, "*"),
+			expectedStmt: "SELECT COUNT(*) FROM (SELECT 1 FROM test INNER JOIN test_2 ON test.id = test_2.test_id WHERE is_active = $1 GROUP BY test.id) AS grouped",
+			expectedArgs: []interface{}{1},
+		},
+		{
+			build: func(q QueryMapper) {
+				q.GroupBy("name")
+			},
+			option:       buildAggregate(sumClause, "total"),
+			expectedStmt: "SELECT COUNT(*) FROM (SELECT 1 FROM test GROUP BY name) AS grouped",
+			expectedErr:  ErrGroupedAggregate,
+		},
+		{
+			build: func(q QueryMapper) {
+				q.WhereNull("deleted_at", true).OrderBy("id").Limit(1)
+			},
+			option:       buildAggregate(maxClause, "total"),
+			expectedStmt: "SELECT MAX(total) FROM test WHERE deleted_at IS NULL",
+		},
+		{
+			build: func(q QueryMapper) {
+				q.Where("name", "=", "gerald")
+			},
+			option:       buildExists(),
+			expectedStmt: "SELECT EXISTS(SELECT 1 FROM test WHERE name = $1)",
+			expectedArgs: []interface{}{"gerald"},
+		},
+	}
+
+	for _, tc := range tests {
+		f.query = newQuery()
+		f.query.builder(setTable("test"))
+
+		if tc.build != nil {
+			tc.build(f)
+		}
+
+		f.query.builder(tc.option)
+		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedArgs, f.query.args)
+		require.Equal(tc.expectedErr, f.query.err)
+	}
+
+	f.query = newQuery()
+	require.NotNil(f.Min("total", 1))
 }
@@ -0,0 +1,83 @@
+package fluent
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// RetryPolicy configures how often a transaction is retried
+// after a serialization failure or a deadlock
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times the
+	// transaction is run, it is run at least once
+	MaxAttempts int
+	// Backoff returns the time to wait before the given
+	// attempt, when nil the transaction is retried immediately
+	Backoff func(attempt int) time.Duration
+	// TxOptions are used to start each transaction,
+	// for example to set the isolation level
+	TxOptions *sql.TxOptions
+}
+
+// ExponentialBackoff doubles the wait time for every attempt
+// starting at base without exceeding max
+func ExponentialBackoff(base, max time.Duration) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		wait := base
+		for i := 2; i < attempt && wait < max; i++ {
+			wait *= 2
+		}
+
+		if wait > max {
+			return max
+		}
+		return wait
+	}
+}
+
+// RetryTransaction runs the function within a transaction and retries
+// the whole transaction when it fails on a serialization failure
+// or a deadlock, any other error is returned right away
+func (f *Fluent) RetryTransaction(ctx context.Context, policy RetryPolicy, fn func(Mapper) error) error {
+	if f.tx != nil {
+		return ErrTxStarted
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && policy.Backoff != nil {
+			timer := time.NewTimer(policy.Backoff(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		err = f.runTransaction(ctx, policy.TxOptions, fn)
+		if err == nil || !isRetryable(err) || attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		if f.query.isDebug() {
+			log.Printf("Retrying transaction after attempt %d of %d: %s", attempt, policy.MaxAttempts, err)
+		}
+	}
+}
+
+// isRetryable checks if the transaction failed on
+// an error that is safe to retry
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == serializationFailure || pqErr.Code == deadlockDetected
+}
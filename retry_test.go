@@ -0,0 +1,81 @@
+package fluent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IsRetryable(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{
+			err:      &pq.Error{Code: "40001"},
+			expected: true,
+		},
+		{
+			err:      &pq.Error{Code: "40P01"},
+			expected: true,
+		},
+		{
+			err:      fmt.Errorf("Insert failed: %w", &pq.Error{Code: "40001"}),
+			expected: true,
+		},
+		{
+			err:      &pq.Error{Code: "23505"},
+			expected: false,
+		},
+		{
+			err:      errors.New("40001"),
+			expected: false,
+		},
+	}
+
+	for _, tc := range tests {
+		require.Equal(tc.expected, isRetryable(tc.err))
+	}
+}
+
+func Test_ExponentialBackoff(t *testing.T) {
+	require := require.New(t)
+
+	backoff := ExponentialBackoff(10*time.Millisecond, 50*time.Millisecond)
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{
+			attempt:  2,
+			expected: 10 * time.Millisecond,
+		},
+		{
+			attempt:  3,
+			expected: 20 * time.Millisecond,
+		},
+		{
+			attempt:  4,
+			expected: 40 * time.Millisecond,
+		},
+		{
+			attempt:  5,
+			expected: 50 * time.Millisecond,
+		},
+		{
+			attempt:  100,
+			expected: 50 * time.Millisecond,
+		},
+	}
+
+	for _, tc := range tests {
+		require.Equal(tc.expected, backoff(tc.attempt))
+	}
+}
@@ -1,45 +1,251 @@
 package fluent
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	scannerTag = "sql"
+
+	// keepZeroOption writes the value of the field even when it's zero,
+	// by default or with the omitempty option zero values are skipped
+	keepZeroOption = "keepzero"
+	// primaryKeyOption marks the column as the primary key
+	// which is returned when inserting a record
+	primaryKeyOption = "pk"
+	// jsonOption marshals the field to JSON when writing
+	// and unmarshals the column when scanning
+	jsonOption = "json"
+	// arrayOption writes and scans the slice as a Postgres array
+	arrayOption = "array"
+	// prefixOption maps the fields of a nested struct to the prefixed
+	// columns, the fields are only scanned and never written
+	prefixOption = "prefix"
+)
+
+// Database types of the columns which values are kept as bytes,
+// the values of the other types are returned as text by the driver
+const (
+	byteaType = "BYTEA"
+	jsonType  = "JSON"
+	jsonbType = "JSONB"
+)
+
+// ratScale is the number of decimals a big.Rat is written with,
+// fractions that don't fit are rounded
+const ratScale = 32
+
+var (
+	scannerInterface = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valuerInterface  = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType         = reflect.TypeOf(time.Time{})
+	ratType          = reflect.TypeOf(big.Rat{})
 )
 
-const scannerTag = "sql"
+// tagOptions are the comma separated options after the column name
+type tagOptions []string
 
+// parseTag splits the tag into the column name and the options
+func parseTag(tag string) (string, tagOptions) {
+	parts := strings.Split(tag, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	return parts[0], tagOptions(parts[1:])
+}
+
+// contains checks if the option is set
+func (o tagOptions) contains(option string) bool {
+	return contains(o, option)
+}
+
+// value returns the value of a key=value option
+func (o tagOptions) value(option string) (string, bool) {
+	for _, opt := range o {
+		if strings.HasPrefix(opt, option+"=") {
+			return opt[len(option)+1:], true
+		}
+	}
+	return "", false
+}
+
+// scanner decodes the value of a column depending on its database type
 type scanner struct {
-	value interface{}
+	typeName string
+	value    interface{}
+}
+
+// one and all scan the rows into a struct or a map, in strict mode
+// a NULL value for a struct field that can't be NULL returns an error
+type one struct {
+	strict bool
 }
+type all struct {
+	strict bool
+}
+
+// pluck scans the first column of each row into a slice
+type pluck struct{}
 
-type one struct{}
-type all struct{}
+// pluckMap scans the first column of each row as
+// key and the second column as value into a map
+type pluckMap struct{}
 
 type scannerType interface {
-	scan(s interface{}, vals map[string]interface{}) error
+	scan(s interface{}, columns []string, vals map[string]interface{}) error
+	// noRows returns the error when the query didn't return any rows
+	noRows() error
 }
 
-func (o *one) scan(s interface{}, vals map[string]interface{}) error {
+func (o *one) scan(s interface{}, columns []string, vals map[string]interface{}) error {
+	switch m := s.(type) {
+	case map[string]interface{}:
+		if m == nil {
+			return fmt.Errorf("The provided map is nil")
+		}
+		copyMap(m, vals)
+		return nil
+	case *map[string]interface{}:
+		if m == nil {
+			return fmt.Errorf("The provided pointer is nil")
+		}
+		if *m == nil {
+			*m = make(map[string]interface{}, len(vals))
+		}
+		copyMap(*m, vals)
+		return nil
+	}
+
+	if o.strict {
+		if err := checkNull(s, vals); err != nil {
+			return err
+		}
+	}
 	return scanStruct(s, vals)
 }
 
-func (a *all) scan(s interface{}, vals map[string]interface{}) error {
+func (o *one) noRows() error {
+	return ErrNoRows
+}
+
+func (a *all) scan(s interface{}, columns []string, vals map[string]interface{}) error {
+	if m, ok := s.(*[]map[string]interface{}); ok {
+		if m == nil {
+			return fmt.Errorf("The provided pointer is nil")
+		}
+
+		row := make(map[string]interface{}, len(vals))
+		copyMap(row, vals)
+		*m = append(*m, row)
+		return nil
+	}
+
+	if a.strict {
+		if err := checkNull(s, vals); err != nil {
+			return err
+		}
+	}
 	return scanStructSlice(s, vals)
 }
 
-// Scan set the value and check if we need to convert it
+func (a *all) noRows() error {
+	return nil
+}
+
+func (p *pluck) scan(s interface{}, columns []string, vals map[string]interface{}) error {
+	valOf := reflect.ValueOf(s)
+	if valOf.Kind() != reflect.Ptr || valOf.IsNil() {
+		return fmt.Errorf("The provided type is not a pointer")
+	}
+
+	valOf = valOf.Elem()
+	if valOf.Kind() != reflect.Slice {
+		return fmt.Errorf("The provided value is not a slice")
+	}
+
+	val := reflect.New(valOf.Type().Elem()).Elem()
+	if v := vals[columns[0]]; v != nil {
+		if err := setFieldValue(val, v); err != nil {
+			return fmt.Errorf("Column %s: %s", columns[0], err)
+		}
+	}
+
+	valOf.Set(reflect.Append(valOf, val))
+	return nil
+}
+
+func (p *pluck) noRows() error {
+	return nil
+}
+
+func (p *pluckMap) scan(s interface{}, columns []string, vals map[string]interface{}) error {
+	if len(columns) < 2 {
+		return fmt.Errorf("The query should return a key and a value column")
+	}
+
+	valOf := reflect.Indirect(reflect.ValueOf(s))
+	if valOf.Kind() != reflect.Map {
+		return fmt.Errorf("The provided value is not a map")
+	}
+
+	if valOf.IsNil() {
+		if !valOf.CanSet() {
+			return fmt.Errorf("The provided map is nil")
+		}
+		valOf.Set(reflect.MakeMap(valOf.Type()))
+	}
+
+	key := reflect.New(valOf.Type().Key()).Elem()
+	val := reflect.New(valOf.Type().Elem()).Elem()
+	for i, v := range []reflect.Value{key, val} {
+		if vals[columns[i]] == nil {
+			continue
+		}
+
+		if err := setFieldValue(v, vals[columns[i]]); err != nil {
+			return fmt.Errorf("Column %s: %s", columns[i], err)
+		}
+	}
+
+	valOf.SetMapIndex(key, val)
+	return nil
+}
+
+func (p *pluckMap) noRows() error {
+	return nil
+}
+
+func copyMap(dst, src map[string]interface{}) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+// Scan set the value and check if we need to convert it, text and numeric
+// values come back as []uint8 and are converted to a string so no
+// leading zeros or precision are lost. Binary and JSON values
+// are copied since the driver reuses the bytes
 func (sc *scanner) Scan(val interface{}) error {
-	switch val.(type) {
-	case []byte:
-		// Strings and floats come back as []uint8
-		if v, ok := val.([]uint8); ok {
+	if v, ok := val.([]uint8); ok {
+		switch sc.typeName {
+		case byteaType, jsonType, jsonbType:
+			val = append([]byte(nil), v...)
+		default:
 			val = string(v)
-			if f, err := strconv.ParseFloat(val.(string), 64); err == nil {
-				val = f
-			}
 		}
 	}
+
 	sc.value = val
 	return nil
 }
@@ -55,76 +261,292 @@ func scanStruct(s interface{}, vals map[string]interface{}) error {
 		return fmt.Errorf("The provided interface is not a struct")
 	}
 
-	for i := 0; i < valOf.Type().NumField(); i++ {
-		field := valOf.Field(i)
+	sf := getStructFields(valOf.Type())
+	for column, val := range vals {
+		f, ok := sf.byColumn[column]
+		if !ok {
+			continue
+		}
 
-		tag := valOf.Type().Field(i).Tag.Get(scannerTag)
-		// Skip empty tags
-		if len(strings.TrimSpace(tag)) == 0 {
+		// Reset the field so no stale data is left in a reused struct,
+		// a nil pointer to a struct isn't allocated for a NULL value
+		if val == nil {
+			field, ok := fieldValue(valOf, f.index)
+			if !ok {
+				continue
+			}
+			if !field.CanSet() {
+				return fmt.Errorf("Can't set the value for field: %s", f.name)
+			}
+
+			if err := setNull(field); err != nil {
+				return fmt.Errorf("Field %s: %s", f.name, err)
+			}
 			continue
 		}
-		fieldName := valOf.Type().Field(i).Name
 
+		field := fieldByIndex(valOf, f.index)
 		if !field.CanSet() {
-			return fmt.Errorf("Can't set the value for field: %s", fieldName)
+			return fmt.Errorf("Can't set the value for field: %s", f.name)
 		}
 
-		if field.Kind() == reflect.Ptr {
-			ptr := reflect.New(field.Type().Elem()).Interface()
-			if err := scanStruct(ptr, vals); err != nil {
-				return err
-			}
+		if err := f.convert(field, val); err != nil {
+			return fmt.Errorf("Field %s: %s", f.name, err)
+		}
+	}
 
-			field.Set(reflect.ValueOf(ptr))
+	// Set the pointers of a reused struct back to nil when all their columns are NULL
+	for _, p := range sf.pointers {
+		if !allNull(p.columns, vals) {
+			continue
 		}
 
-		// Check if the tag exists
-		if val := vals[tag]; val != nil {
-			if err := setFieldValue(field, val); err != nil {
-				return fmt.Errorf("Field %s: %s", fieldName, err)
-			}
+		if field, ok := fieldValue(valOf, p.index); ok && field.CanSet() {
+			field.Set(reflect.Zero(field.Type()))
 		}
 	}
 
 	return nil
 }
 
-// Set the value depending on the field type
-func setFieldValue(field reflect.Value, v interface{}) error {
-	switch field.Kind() {
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		val, ok := v.(int64)
+// allNull checks if at least one of the columns is scanned and all the scanned columns are NULL
+func allNull(columns []string, vals map[string]interface{}) bool {
+	var scanned bool
+	for _, column := range columns {
+		val, ok := vals[column]
 		if !ok {
-			// Try again to cast to int64
-			if intVal, ok := v.(int); ok {
-				val = int64(intVal)
-			} else {
-				return fmt.Errorf("unable to set the integer value")
-			}
+			continue
 		}
-		field.SetInt(val)
-	case reflect.Float32, reflect.Float64:
-		val, ok := v.(float64)
-		if !ok {
-			return fmt.Errorf("unable to set the float value")
+		if val != nil {
+			return false
 		}
-		field.SetFloat(val)
-	case reflect.String:
-		val, ok := v.(string)
-		if !ok {
-			return fmt.Errorf("unable to set the string value")
+		scanned = true
+	}
+
+	return scanned
+}
+
+// setNull sets the field to NULL, a sql.Scanner scans the NULL value
+// and the other fields are set to their zero value
+func setNull(field reflect.Value) error {
+	if scanner, ok := field.Addr().Interface().(sql.Scanner); ok {
+		return scanner.Scan(nil)
+	}
+
+	field.Set(reflect.Zero(field.Type()))
+	return nil
+}
+
+// checkNull returns ErrNullValue when a NULL value is scanned into a struct
+// field that can't be NULL, the fields of a nested pointer which is left
+// nil because all its columns are NULL aren't checked
+func checkNull(s interface{}, vals map[string]interface{}) error {
+	typeOf := structType(s)
+	if typeOf == nil {
+		return nil
+	}
+
+	sf := getStructFields(typeOf)
+	skip := map[string]bool{}
+	for _, p := range sf.pointers {
+		if !allNull(p.columns, vals) {
+			continue
+		}
+		for _, column := range p.columns {
+			skip[column] = true
+		}
+	}
+
+	for column, val := range vals {
+		if f, ok := sf.byColumn[column]; ok && val == nil && !f.nullable && !skip[column] {
+			return fmt.Errorf("Field %s: %w", f.name, ErrNullValue)
 		}
-		field.SetString(val)
+	}
+
+	return nil
+}
+
+// converter sets the scanned value on the field
+type converter func(field reflect.Value, v interface{}) error
+
+// converterFor returns the converter for the field type,
+// a field implementing sql.Scanner scans the value itself
+func converterFor(typeOf reflect.Type) converter {
+	if reflect.PtrTo(typeOf).Implements(scannerInterface) {
+		return setScanner
+	}
+
+	if typeOf == ratType {
+		return setRat
+	}
+
+	switch typeOf.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setInt
+	case reflect.Float32, reflect.Float64:
+		return setFloat
+	case reflect.String:
+		return setString
 	case reflect.Bool:
-		val, ok := v.(bool)
-		if !ok {
-			return fmt.Errorf("unable to set the bool value")
+		return setBool
+	case reflect.Ptr:
+		if typeOf.Implements(scannerInterface) {
+			return setScannerPtr
+		}
+		return setPtr(converterFor(typeOf.Elem()))
+	case reflect.Slice:
+		if typeOf.Elem().Kind() == reflect.Uint8 {
+			return setBytes
+		}
+	}
+
+	return setValue
+}
+
+// Set the value depending on the field type
+func setFieldValue(field reflect.Value, v interface{}) error {
+	return converterFor(field.Type())(field, v)
+}
+
+func setInt(field reflect.Value, v interface{}) error {
+	val, ok := v.(int64)
+	if !ok {
+		// Try again to cast to int64
+		if intVal, ok := v.(int); ok {
+			val = int64(intVal)
+		} else {
+			return fmt.Errorf("unable to set the integer value")
+		}
+	}
+	field.SetInt(val)
+	return nil
+}
+
+func setFloat(field reflect.Value, v interface{}) error {
+	var val float64
+	switch f := v.(type) {
+	case float64:
+		val = f
+	case string:
+		// Numeric values come back as text
+		var err error
+		if val, err = strconv.ParseFloat(f, 64); err != nil {
+			return fmt.Errorf("unable to set the float value")
 		}
-		field.SetBool(val)
 	default:
-		field.Set(reflect.ValueOf(v))
+		return fmt.Errorf("unable to set the float value")
+	}
+	field.SetFloat(val)
+	return nil
+}
+
+// setRat sets the exact value of a numeric column
+func setRat(field reflect.Value, v interface{}) error {
+	rat := field.Addr().Interface().(*big.Rat)
+	switch r := v.(type) {
+	case string:
+		if _, ok := rat.SetString(r); !ok {
+			return fmt.Errorf("unable to set the rational value")
+		}
+	case int64:
+		rat.SetInt64(r)
+	case float64:
+		rat.SetFloat64(r)
+	default:
+		return fmt.Errorf("unable to set the rational value")
+	}
+	return nil
+}
+
+func setString(field reflect.Value, v interface{}) error {
+	val, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("unable to set the string value")
+	}
+	field.SetString(val)
+	return nil
+}
+
+func setBool(field reflect.Value, v interface{}) error {
+	val, ok := v.(bool)
+	if !ok {
+		return fmt.Errorf("unable to set the bool value")
+	}
+	field.SetBool(val)
+	return nil
+}
+
+// setBytes copies the value since the driver may reuse the bytes
+func setBytes(field reflect.Value, v interface{}) error {
+	switch val := v.(type) {
+	case []byte:
+		field.SetBytes(append([]byte(nil), val...))
+	case string:
+		field.SetBytes([]byte(val))
+	default:
+		return fmt.Errorf("unable to set the bytes value")
+	}
+	return nil
+}
+
+// setJSON unmarshals the JSON value into the field
+func setJSON(field reflect.Value, v interface{}) error {
+	var data []byte
+	switch val := v.(type) {
+	case []byte:
+		data = val
+	case string:
+		data = []byte(val)
+	default:
+		return fmt.Errorf("unable to set the json value")
+	}
+
+	// Reset the field so no stale keys or elements are left
+	field.Set(reflect.Zero(field.Type()))
+	return json.Unmarshal(data, field.Addr().Interface())
+}
+
+// setArray scans the Postgres array into the slice
+func setArray(field reflect.Value, v interface{}) error {
+	field.Set(reflect.Zero(field.Type()))
+	return pq.Array(field.Addr().Interface()).(sql.Scanner).Scan(v)
+}
+
+func setScanner(field reflect.Value, v interface{}) error {
+	return field.Addr().Interface().(sql.Scanner).Scan(v)
+}
+
+// setScannerPtr scans the value into a new sql.Scanner
+func setScannerPtr(field reflect.Value, v interface{}) error {
+	ptr := reflect.New(field.Type().Elem())
+	if err := ptr.Interface().(sql.Scanner).Scan(v); err != nil {
+		return err
 	}
 
+	field.Set(ptr)
+	return nil
+}
+
+// setPtr converts the value into a new value the field points to
+func setPtr(convert converter) converter {
+	return func(field reflect.Value, v interface{}) error {
+		ptr := reflect.New(field.Type().Elem())
+		if err := convert(ptr.Elem(), v); err != nil {
+			return err
+		}
+
+		field.Set(ptr)
+		return nil
+	}
+}
+
+func setValue(field reflect.Value, v interface{}) error {
+	val := reflect.ValueOf(v)
+	if !val.Type().AssignableTo(field.Type()) {
+		return fmt.Errorf("unable to set the %s value", field.Type())
+	}
+
+	field.Set(val)
 	return nil
 }
 
@@ -158,6 +580,32 @@ func scanStructSlice(s interface{}, vals map[string]interface{}) error {
 }
 
 func getStructValues(s interface{}) ([]string, []interface{}, error) {
+	return structValues(s, false)
+}
+
+// getColumnValues returns the values of the struct for the given columns
+func getColumnValues(s interface{}, columns []string) ([]interface{}, error) {
+	cols, args, err := structValues(s, true)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(columns))
+	for i, column := range columns {
+		j := indexOf(cols, column)
+		if j < 0 {
+			return nil, fmt.Errorf("No field found for column: %s", column)
+		}
+		values[i] = args[j]
+	}
+
+	return values, nil
+}
+
+// structValues returns the tagged columns and values of the struct, zero
+// values are skipped unless keepZero or the keepzero tag option is set.
+// The read only fields of prefixed structs are skipped
+func structValues(s interface{}, keepZero bool) ([]string, []interface{}, error) {
 	valOf := reflect.Indirect(reflect.ValueOf(s))
 	if valOf.Kind() != reflect.Struct {
 		return nil, nil, fmt.Errorf("The provided interface is not a struct")
@@ -167,24 +615,217 @@ func getStructValues(s interface{}) ([]string, []interface{}, error) {
 		cols []string
 		args []interface{}
 	)
-	for i := 0; i < valOf.Type().NumField(); i++ {
-		value := valOf.Field(i).Interface()
-		tag := valOf.Type().Field(i).Tag.Get(scannerTag)
-		// Skip empty tags
-		if len(strings.TrimSpace(tag)) == 0 {
+	for _, f := range getStructFields(valOf.Type()).fields {
+		if f.readOnly {
 			continue
 		}
 
-		if !isZero(value) {
+		field, ok := fieldValue(valOf, f.index)
+		if !ok {
+			field = reflect.Zero(f.typ)
+		}
+
+		if keepZero || f.keepZero || !field.IsZero() {
+			value, err := f.value(field)
+			if err != nil {
+				return nil, nil, fmt.Errorf("Field %s: %s", f.name, err)
+			}
+
 			args = append(args, value)
-			cols = append(cols, tag)
+			cols = append(cols, f.column)
 		}
 	}
 
 	return cols, args, nil
 }
 
-// Check if the underlying type of the value is zero
-func isZero(v interface{}) bool {
-	return reflect.DeepEqual(v, reflect.Zero(reflect.TypeOf(v)).Interface())
+// jsonValue returns the field marshalled to JSON, a nil
+// pointer, map or slice is written as NULL
+func jsonValue(field reflect.Value) (interface{}, error) {
+	if isNullable(field.Type()) && field.IsNil() {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(field.Interface())
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// arrayValue returns the slice as a Postgres array, a nil slice is written as NULL
+func arrayValue(field reflect.Value) (interface{}, error) {
+	return pq.Array(field.Interface()).(driver.Valuer).Value()
+}
+
+// valueFor returns the function that returns the value to write of a field of the type
+func valueFor(typeOf reflect.Type) func(reflect.Value) (interface{}, error) {
+	if indirect(typeOf) == ratType {
+		return ratValue
+	}
+
+	if !typeOf.Implements(valuerInterface) && reflect.PtrTo(typeOf).Implements(valuerInterface) {
+		return addrValue
+	}
+	return driverValue
+}
+
+// ratValue returns the big.Rat as a decimal string, a nil pointer is written as NULL
+func ratValue(field reflect.Value) (interface{}, error) {
+	var rat *big.Rat
+	switch r := field.Interface().(type) {
+	case big.Rat:
+		rat = &r
+	case *big.Rat:
+		if r == nil {
+			return nil, nil
+		}
+		rat = r
+	}
+
+	value := rat.FloatString(ratScale)
+	if strings.Contains(value, ".") {
+		value = strings.TrimRight(strings.TrimRight(value, "0"), ".")
+	}
+	return value, nil
+}
+
+// driverValue returns the value of the field to write,
+// a field implementing driver.Valuer returns its own value
+func driverValue(field reflect.Value) (interface{}, error) {
+	value := field.Interface()
+
+	valuer, ok := value.(driver.Valuer)
+	if !ok {
+		return value, nil
+	}
+
+	// A nil pointer is written as NULL instead of calling Value on it
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return nil, nil
+	}
+	return valuer.Value()
+}
+
+// addrValue returns the value of a field implementing driver.Valuer with a
+// pointer receiver, a field that isn't addressable, like the field of a
+// struct passed by value, is copied to call the pointer receiver
+func addrValue(field reflect.Value) (interface{}, error) {
+	if !field.CanAddr() {
+		copied := reflect.New(field.Type()).Elem()
+		copied.Set(field)
+		field = copied
+	}
+
+	return field.Addr().Interface().(driver.Valuer).Value()
+}
+
+// getSliceValues returns the columns and the values of each struct in the
+// slice, the columns are the union of the non zero columns of all the
+// structs and a missing value is set to the default value
+func getSliceValues(s interface{}) ([]string, [][]interface{}, error) {
+	valOf := reflect.Indirect(reflect.ValueOf(s))
+	if valOf.Kind() != reflect.Slice {
+		return nil, nil, fmt.Errorf("The provided value is not a slice")
+	}
+
+	var cols []string
+	values := make([]map[string]interface{}, valOf.Len())
+	for i := 0; i < valOf.Len(); i++ {
+		c, a, err := getStructValues(valOf.Index(i).Interface())
+		if err != nil {
+			return nil, nil, err
+		}
+
+		values[i] = make(map[string]interface{}, len(c))
+		for j, col := range c {
+			if !contains(cols, col) {
+				cols = append(cols, col)
+			}
+			values[i][col] = a[j]
+		}
+	}
+
+	rows := make([][]interface{}, len(values))
+	for i, vals := range values {
+		rows[i] = make([]interface{}, len(cols))
+		for j, col := range cols {
+			if val, ok := vals[col]; ok {
+				rows[i][j] = val
+			} else {
+				rows[i][j] = defaultValue{}
+			}
+		}
+	}
+
+	return cols, rows, nil
+}
+
+// getColumns returns the tagged columns of the struct type, including the
+// columns of tagged pointers to a struct. The prefixed columns of a joined
+// table are left out, they have to be selected with an alias
+func getColumns(typeOf reflect.Type) []string {
+	if typeOf.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var columns []string
+	for _, f := range getStructFields(typeOf).fields {
+		if !f.joined {
+			columns = append(columns, f.column)
+		}
+	}
+	return columns
+}
+
+// getPrimaryKey returns the column tagged as primary key of the struct
+// or the structs in the slice, it's empty when no column is tagged
+func getPrimaryKey(s interface{}) string {
+	typeOf := structType(s)
+	if typeOf == nil {
+		return ""
+	}
+
+	return getStructFields(typeOf).primaryKey
+}
+
+// checkPrimaryKey checks if the primary key field of the struct
+// is an integer, Insert, InsertMany and Upsert return int ids
+func checkPrimaryKey(s interface{}) error {
+	typeOf := structType(s)
+	if typeOf == nil {
+		return nil
+	}
+
+	sf := getStructFields(typeOf)
+	pk := sf.primaryKey
+	if len(pk) == 0 {
+		pk = defaultPrimaryKey
+	}
+
+	f, ok := sf.byColumn[pk]
+	if !ok {
+		return nil
+	}
+
+	switch indirect(f.typ).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return nil
+	}
+	return fmt.Errorf("Field %s: %w", f.name, ErrPrimaryKeyType)
+}
+
+// structType returns the type of the struct or the structs in
+// the slice, it's nil when the value doesn't hold a struct
+func structType(s interface{}) reflect.Type {
+	typeOf := reflect.TypeOf(s)
+	for typeOf != nil && (typeOf.Kind() == reflect.Ptr || typeOf.Kind() == reflect.Slice) {
+		typeOf = typeOf.Elem()
+	}
+
+	if typeOf == nil || typeOf.Kind() != reflect.Struct {
+		return nil
+	}
+	return typeOf
 }
@@ -1,6 +1,14 @@
 package fluent
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,9 +31,8 @@ type Inherit struct {
 func Test_Scan(t *testing.T) {
 	require := require.New(t)
 
-	sc := &scanner{}
-
 	tests := []struct {
+		typeName string
 		value    interface{}
 		expected interface{}
 	}{
@@ -38,8 +45,29 @@ func Test_Scan(t *testing.T) {
 			expected: string("test"),
 		},
 		{
+			typeName: "NUMERIC",
 			value:    []uint8("12.00"),
-			expected: float64(12.00),
+			expected: "12.00",
+		},
+		{
+			typeName: "NUMERIC",
+			value:    []uint8("12345678901234567890.1234567890"),
+			expected: "12345678901234567890.1234567890",
+		},
+		{
+			typeName: "BPCHAR",
+			value:    []uint8("01234"),
+			expected: "01234",
+		},
+		{
+			typeName: "JSONB",
+			value:    []uint8(`{"total": 12}`),
+			expected: []byte(`{"total": 12}`),
+		},
+		{
+			typeName: "BYTEA",
+			value:    []uint8{0, 1},
+			expected: []byte{0, 1},
 		},
 		{
 			value:    false,
@@ -48,9 +76,17 @@ func Test_Scan(t *testing.T) {
 	}
 
 	for _, tc := range tests {
+		sc := &scanner{typeName: tc.typeName}
 		sc.Scan(tc.value)
 		require.Equal(tc.expected, sc.value)
 	}
+
+	// The bytes are copied since the driver reuses them
+	value := []uint8{0, 1}
+	sc := &scanner{typeName: "BYTEA"}
+	sc.Scan(value)
+	value[0] = 1
+	require.Equal([]byte{0, 1}, sc.value)
 }
 
 func Test_ScanStruct(t *testing.T) {
@@ -339,3 +375,738 @@ func Test_GetStructValues(t *testing.T) {
 		}
 	}
 }
+
+func Test_GetSliceValues(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		records      interface{}
+		expectedCols []string
+		expectedRows [][]interface{}
+		expectedErr  bool
+	}{
+		{
+			records: []scanTest{
+				{Name: "gerald", Total: 12.00},
+				{Name: "henry"},
+				{IsActive: true},
+			},
+			expectedCols: []string{"name", "total", "is_active"},
+			expectedRows: [][]interface{}{
+				{"gerald", 12.00, defaultValue{}},
+				{"henry", defaultValue{}, defaultValue{}},
+				{defaultValue{}, defaultValue{}, true},
+			},
+		},
+		{
+			records: &[]*scanTest{
+				{ID: 1},
+			},
+			expectedCols: []string{"id"},
+			expectedRows: [][]interface{}{
+				{1},
+			},
+		},
+		{
+			records:      []scanTest{},
+			expectedRows: [][]interface{}{},
+		},
+		{
+			records:     scanTest{},
+			expectedErr: true,
+		},
+		{
+			records:     []int{1},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		cols, rows, err := getSliceValues(tc.records)
+		if tc.expectedErr {
+			require.NotNil(err)
+			continue
+		}
+
+		require.Nil(err)
+		require.Equal(tc.expectedCols, cols)
+		require.Equal(tc.expectedRows, rows)
+	}
+}
+
+func Test_GetColumnValues(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		testStruct     scanTest
+		columns        []string
+		expectedValues []interface{}
+		expectedErr    bool
+	}{
+		{
+			testStruct:     scanTest{ID: 1, Name: "gerald"},
+			columns:        []string{"name", "id", "total"},
+			expectedValues: []interface{}{"gerald", 1, 0.00},
+		},
+		{
+			testStruct:     scanTest{},
+			columns:        []string{"is_active"},
+			expectedValues: []interface{}{false},
+		},
+		{
+			testStruct:  scanTest{},
+			columns:     []string{"unknown"},
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		values, err := getColumnValues(tc.testStruct, tc.columns)
+		if tc.expectedErr {
+			require.NotNil(err)
+			continue
+		}
+
+		require.Nil(err)
+		require.Equal(tc.expectedValues, values)
+	}
+}
+
+type tagTest struct {
+	ID       int    `sql:"id,omitempty"`
+	Name     string `sql:" name , keepzero "`
+	IsActive bool   `sql:"is_active,keepzero"`
+	Skip     string `sql:",keepzero"`
+}
+
+func Test_ParseTag(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		tag             string
+		expectedName    string
+		expectedOptions tagOptions
+	}{
+		{
+			tag:             "id",
+			expectedName:    "id",
+			expectedOptions: tagOptions{},
+		},
+		{
+			tag:             "is_active,keepzero",
+			expectedName:    "is_active",
+			expectedOptions: tagOptions{"keepzero"},
+		},
+		{
+			tag:             " name , omitempty ",
+			expectedName:    "name",
+			expectedOptions: tagOptions{"omitempty"},
+		},
+		{
+			tag:             "",
+			expectedName:    "",
+			expectedOptions: tagOptions{},
+		},
+	}
+
+	for _, tc := range tests {
+		name, opts := parseTag(tc.tag)
+		require.Equal(tc.expectedName, name)
+		require.Equal(tc.expectedOptions, opts)
+	}
+}
+
+func Test_GetStructValuesKeepZero(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		testStruct   tagTest
+		expectedCols []string
+		expectedArgs []interface{}
+	}{
+		{
+			testStruct:   tagTest{},
+			expectedCols: []string{"name", "is_active"},
+			expectedArgs: []interface{}{"", false},
+		},
+		{
+			testStruct:   tagTest{ID: 1, IsActive: true},
+			expectedCols: []string{"id", "name", "is_active"},
+			expectedArgs: []interface{}{1, "", true},
+		},
+	}
+
+	for _, tc := range tests {
+		cols, args, err := getStructValues(tc.testStruct)
+		require.Nil(err)
+		require.Equal(tc.expectedCols, cols)
+		require.Equal(tc.expectedArgs, args)
+	}
+
+	record := tagTest{}
+	err := scanStruct(&record, map[string]interface{}{"id": 1, "name": "gerald", "is_active": true})
+	require.Nil(err)
+	require.Equal(tagTest{ID: 1, Name: "gerald", IsActive: true}, record)
+}
+
+type pkTest struct {
+	UUID string `sql:"uuid,pk"`
+	Name string `sql:"name"`
+}
+
+func Test_GetPrimaryKey(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{
+			value:    pkTest{},
+			expected: "uuid",
+		},
+		{
+			value:    &pkTest{},
+			expected: "uuid",
+		},
+		{
+			value:    []*pkTest{},
+			expected: "uuid",
+		},
+		{
+			value:    scanTest{},
+			expected: "",
+		},
+		{
+			value:    1,
+			expected: "",
+		},
+		{
+			value:    nil,
+			expected: "",
+		},
+	}
+
+	for _, tc := range tests {
+		require.Equal(tc.expected, getPrimaryKey(tc.value))
+	}
+}
+
+func Test_CheckPrimaryKey(t *testing.T) {
+	require := require.New(t)
+
+	type idTest struct {
+		ID string `sql:"id"`
+	}
+
+	tests := []struct {
+		value       interface{}
+		expectedErr bool
+	}{
+		{
+			value: scanTest{},
+		},
+		{
+			value: []*nestedTest{},
+		},
+		{
+			value: tagTest{},
+		},
+		{
+			value:       pkTest{},
+			expectedErr: true,
+		},
+		{
+			value:       []pkTest{},
+			expectedErr: true,
+		},
+		{
+			value:       &idTest{},
+			expectedErr: true,
+		},
+		{
+			value: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		err := checkPrimaryKey(tc.value)
+		if tc.expectedErr {
+			require.True(errors.Is(err, ErrPrimaryKeyType))
+			continue
+		}
+		require.Nil(err)
+	}
+
+	f := &Fluent{query: newQuery()}
+	_, err := f.Insert(pkTest{Name: "gerald"})
+	require.True(errors.Is(err, ErrPrimaryKeyType))
+}
+
+func Test_ScanMap(t *testing.T) {
+	require := require.New(t)
+
+	rows := []map[string]interface{}{
+		{"id": int64(1), "name": "gerald"},
+		{"id": int64(2), "name": "henry"},
+	}
+	columns := []string{"id", "name"}
+
+	record := map[string]interface{}{}
+	require.Nil((&one{}).scan(record, columns, rows[0]))
+	require.Equal(rows[0], record)
+
+	var recordPtr map[string]interface{}
+	require.Nil((&one{}).scan(&recordPtr, columns, rows[1]))
+	require.Equal(rows[1], recordPtr)
+
+	records := []map[string]interface{}{}
+	for _, row := range rows {
+		require.Nil((&all{}).scan(&records, columns, row))
+	}
+	require.Equal(rows, records)
+
+	// The rows are copied so reusing the values map is safe
+	rows[0]["name"] = "marcel"
+	require.Equal("gerald", records[0]["name"])
+
+	var nilMap map[string]interface{}
+	require.NotNil((&one{}).scan(nilMap, columns, rows[0]))
+	require.NotNil((&one{}).scan((*map[string]interface{})(nil), columns, rows[0]))
+	require.NotNil((&all{}).scan((*[]map[string]interface{})(nil), columns, rows[0]))
+	require.NotNil((&one{}).scan(nil, columns, rows[0]))
+	require.NotNil((&one{}).scan((*scanTest)(nil), columns, rows[0]))
+	require.NotNil((&all{}).scan(nil, columns, rows[0]))
+	require.NotNil((&all{}).scan((*[]scanTest)(nil), columns, rows[0]))
+}
+
+func Test_Pluck(t *testing.T) {
+	require := require.New(t)
+
+	names := []string{}
+	ids := []int64{}
+	for _, row := range []map[string]interface{}{
+		{"id": int64(1), "name": "gerald"},
+		{"id": int64(2), "name": "henry"},
+		{"id": int64(3), "name": nil},
+	} {
+		require.Nil((&pluck{}).scan(&names, []string{"name"}, row))
+		require.Nil((&pluck{}).scan(&ids, []string{"id"}, row))
+	}
+
+	require.Equal([]string{"gerald", "henry", ""}, names)
+	require.Equal([]int64{1, 2, 3}, ids)
+
+	require.NotNil((&pluck{}).scan(names, []string{"name"}, map[string]interface{}{"name": "gerald"}))
+	require.NotNil((&pluck{}).scan(&ids, []string{"name"}, map[string]interface{}{"name": "gerald"}))
+	require.NotNil((&pluck{}).scan(nil, []string{"name"}, map[string]interface{}{"name": "gerald"}))
+	require.NotNil((&pluck{}).scan((*[]string)(nil), []string{"name"}, map[string]interface{}{"name": "gerald"}))
+}
+
+func Test_PluckMap(t *testing.T) {
+	require := require.New(t)
+
+	rows := []map[string]interface{}{
+		{"id": int64(1), "total": 12.00},
+		{"id": int64(2), "total": 10.00},
+	}
+	columns := []string{"id", "total"}
+
+	var totals map[int]float64
+	for _, row := range rows {
+		require.Nil((&pluckMap{}).scan(&totals, columns, row))
+	}
+	require.Equal(map[int]float64{1: 12.00, 2: 10.00}, totals)
+
+	names := map[string]int64{}
+	require.Nil((&pluckMap{}).scan(names, []string{"name", "id"}, map[string]interface{}{"name": "gerald", "id": int64(1)}))
+	require.Equal(map[string]int64{"gerald": 1}, names)
+
+	var nilMap map[int]float64
+	require.NotNil((&pluckMap{}).scan(nilMap, columns, rows[0]))
+	require.NotNil((&pluckMap{}).scan(&totals, []string{"id"}, rows[0]))
+	require.NotNil((&pluckMap{}).scan(&[]int{}, columns, rows[0]))
+}
+
+func Test_GetColumns(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		value    interface{}
+		expected []string
+	}{
+		{
+			value:    scanTest{},
+			expected: []string{"row_count", "id", "name", "total", "is_active", "created_at"},
+		},
+		{
+			value:    tagTest{},
+			expected: []string{"id", "name", "is_active"},
+		},
+		{
+			value:    1,
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		require.Equal(tc.expected, getColumns(reflect.TypeOf(tc.value)))
+	}
+}
+
+// upper is scanned in upper case and written in lower case
+type upper string
+
+func (u *upper) Scan(v interface{}) error {
+	s, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("unable to scan %T", v)
+	}
+	*u = upper(strings.ToUpper(s))
+	return nil
+}
+
+func (u upper) Value() (driver.Value, error) {
+	return strings.ToLower(string(u)), nil
+}
+
+type valuerTest struct {
+	Name upper           `sql:"name"`
+	Code *upper          `sql:"code,keepzero"`
+	Nick sql.NullString  `sql:"nick"`
+	Data json.RawMessage `sql:"data"`
+}
+
+func Test_ScanStructScanner(t *testing.T) {
+	require := require.New(t)
+
+	code := upper("X")
+	record := valuerTest{}
+	err := scanStruct(&record, map[string]interface{}{
+		"name": "gerald",
+		"code": "x",
+		"nick": "gee",
+		"data": `{"total": 12}`,
+	})
+	require.Nil(err)
+	require.Equal(valuerTest{
+		Name: "GERALD",
+		Code: &code,
+		Nick: sql.NullString{String: "gee", Valid: true},
+		Data: json.RawMessage(`{"total": 12}`),
+	}, record)
+
+	err = scanStruct(&record, map[string]interface{}{"name": 1})
+	require.NotNil(err)
+
+	err = scanStruct(&record, map[string]interface{}{"data": 1})
+	require.NotNil(err)
+
+	names := []sql.NullString{}
+	require.Nil((&pluck{}).scan(&names, []string{"name"}, map[string]interface{}{"name": "gerald"}))
+	require.Equal([]sql.NullString{{String: "gerald", Valid: true}}, names)
+}
+
+type money struct {
+	Cents int64
+}
+
+func (m *money) Value() (driver.Value, error) {
+	return m.Cents, nil
+}
+
+type moneyTest struct {
+	Total money `sql:"total"`
+}
+
+func Test_GetStructValuesValuer(t *testing.T) {
+	require := require.New(t)
+
+	cols, args, err := getStructValues(valuerTest{Name: "GERALD", Nick: sql.NullString{String: "gee", Valid: true}})
+	require.Nil(err)
+	require.Equal([]string{"name", "code", "nick"}, cols)
+	require.Equal([]interface{}{"gerald", nil, "gee"}, args)
+
+	code := upper("X")
+	cols, args, err = getStructValues(&valuerTest{Code: &code})
+	require.Nil(err)
+	require.Equal([]string{"code"}, cols)
+	require.Equal([]interface{}{"x"}, args)
+
+	// The pointer receiver is called for a struct passed by value or by pointer
+	for _, record := range []interface{}{moneyTest{money{5}}, &moneyTest{money{5}}} {
+		_, args, err = getStructValues(record)
+		require.Nil(err)
+		require.Equal([]interface{}{int64(5)}, args)
+	}
+}
+
+type nullTest struct {
+	Name      *string        `sql:"name"`
+	Total     *float64       `sql:"total"`
+	CreatedAt *time.Time     `sql:"created_at"`
+	Nick      sql.NullString `sql:"nick"`
+	Code      *upper         `sql:"code"`
+	Count     int            `sql:"count"`
+}
+
+func Test_ScanStructNull(t *testing.T) {
+	require := require.New(t)
+
+	timestamp := time.Now()
+	record := nullTest{}
+	err := scanStruct(&record, map[string]interface{}{
+		"name":       "gerald",
+		"total":      12.00,
+		"created_at": timestamp,
+		"nick":       "gee",
+		"code":       "x",
+		"count":      2,
+	})
+	require.Nil(err)
+	require.Equal("gerald", *record.Name)
+	require.Equal(12.00, *record.Total)
+	require.Equal(timestamp, *record.CreatedAt)
+	require.Equal(sql.NullString{String: "gee", Valid: true}, record.Nick)
+	require.Equal(upper("X"), *record.Code)
+	require.Equal(2, record.Count)
+
+	nulls := map[string]interface{}{
+		"name":       nil,
+		"total":      nil,
+		"created_at": nil,
+		"nick":       nil,
+		"code":       nil,
+		"count":      nil,
+	}
+	require.Nil(scanStruct(&record, nulls))
+	require.Equal(nullTest{}, record)
+
+	// A NULL value for a field that can't be NULL
+	// is only an error in strict mode
+	err = (&one{strict: true}).scan(&record, nil, nulls)
+	require.True(errors.Is(err, ErrNullValue))
+
+	records := []nullTest{}
+	err = (&all{strict: true}).scan(&records, nil, nulls)
+	require.True(errors.Is(err, ErrNullValue))
+	require.Len(records, 0)
+
+	delete(nulls, "count")
+	require.Nil((&one{strict: true}).scan(&record, nil, nulls))
+	require.Nil((&all{strict: true}).scan(&records, nil, nulls))
+	require.Len(records, 1)
+
+	require.Equal([]string{"name", "total", "created_at", "nick", "code", "count"}, getColumns(reflect.TypeOf(record)))
+}
+
+type numericTest struct {
+	Total    float64  `sql:"total"`
+	Text     string   `sql:"text"`
+	Exact    big.Rat  `sql:"exact"`
+	ExactPtr *big.Rat `sql:"exact_ptr"`
+}
+
+func Test_ScanStructNumeric(t *testing.T) {
+	require := require.New(t)
+
+	record := numericTest{}
+	err := scanStruct(&record, map[string]interface{}{
+		"total":     "12.50",
+		"text":      "12345678901234567890.1234567890",
+		"exact":     "12345678901234567890.1234567890",
+		"exact_ptr": "0.1",
+	})
+	require.Nil(err)
+	require.Equal(12.50, record.Total)
+	require.Equal("12345678901234567890.1234567890", record.Text)
+	require.Equal("12345678901234567890.1234567890", record.Exact.FloatString(10))
+	require.Equal("1/10", record.ExactPtr.String())
+
+	err = scanStruct(&record, map[string]interface{}{"exact": "total"})
+	require.NotNil(err)
+
+	totals := []float64{}
+	require.Nil((&pluck{}).scan(&totals, []string{"total"}, map[string]interface{}{"total": "12.50"}))
+	require.Equal([]float64{12.50}, totals)
+
+	tests := []struct {
+		rat      *big.Rat
+		expected string
+		exact    bool
+	}{
+		{rat: big.NewRat(1, 10), expected: "0.1", exact: true},
+		{rat: big.NewRat(-1250, 100), expected: "-12.5", exact: true},
+		{rat: big.NewRat(12, 1), expected: "12", exact: true},
+		{rat: big.NewRat(1, 3), expected: "0.33333333333333333333333333333333"},
+	}
+	for _, tc := range tests {
+		cols, args, err := getStructValues(numericTest{Exact: *tc.rat, ExactPtr: tc.rat})
+		require.Nil(err)
+		require.Equal([]string{"exact", "exact_ptr"}, cols)
+		require.Equal([]interface{}{tc.expected, tc.expected}, args)
+
+		// The written value scans back into the same rational
+		scanned := numericTest{}
+		require.Nil(scanStruct(&scanned, map[string]interface{}{"exact": args[0], "exact_ptr": args[1]}))
+		require.Equal(tc.exact, tc.rat.Cmp(&scanned.Exact) == 0)
+		require.Equal(tc.exact, tc.rat.Cmp(scanned.ExactPtr) == 0)
+	}
+
+	values, err := getColumnValues(numericTest{}, []string{"exact_ptr"})
+	require.Nil(err)
+	require.Equal([]interface{}{nil}, values)
+}
+
+type settings struct {
+	Theme string `json:"theme"`
+	Beta  bool   `json:"beta"`
+}
+
+type jsonTest struct {
+	Settings settings               `sql:"settings,json"`
+	Prefs    *settings              `sql:"prefs,json"`
+	Extra    map[string]interface{} `sql:"extra,json"`
+}
+
+func Test_JSONOption(t *testing.T) {
+	require := require.New(t)
+
+	cols, args, err := getStructValues(jsonTest{
+		Settings: settings{Theme: "dark"},
+		Extra:    map[string]interface{}{"total": 12},
+	})
+	require.Nil(err)
+	require.Equal([]string{"settings", "extra"}, cols)
+	require.Equal([]interface{}{`{"theme":"dark","beta":false}`, `{"total":12}`}, args)
+
+	values, err := getColumnValues(jsonTest{}, []string{"prefs", "extra"})
+	require.Nil(err)
+	require.Equal([]interface{}{nil, nil}, values)
+
+	record := jsonTest{Extra: map[string]interface{}{"stale": true}}
+	err = scanStruct(&record, map[string]interface{}{
+		"settings": []byte(`{"theme":"dark","beta":true}`),
+		"prefs":    `{"theme":"light"}`,
+		"extra":    []byte(`{"total":12}`),
+	})
+	require.Nil(err)
+	require.Equal(jsonTest{
+		Settings: settings{Theme: "dark", Beta: true},
+		Prefs:    &settings{Theme: "light"},
+		Extra:    map[string]interface{}{"total": float64(12)},
+	}, record)
+
+	require.Nil(scanStruct(&record, map[string]interface{}{"prefs": nil}))
+	require.Nil(record.Prefs)
+
+	require.NotNil(scanStruct(&record, map[string]interface{}{"settings": []byte(`{`)}))
+	require.NotNil(scanStruct(&record, map[string]interface{}{"settings": 12}))
+	require.Equal([]string{"settings", "prefs", "extra"}, getColumns(reflect.TypeOf(record)))
+}
+
+type arrayTest struct {
+	Tags   []string  `sql:"tags,array"`
+	Scores []int64   `sql:"scores,array"`
+	Ratios []float64 `sql:"ratios,array"`
+	Names  []string  `sql:"names"`
+}
+
+func Test_ArrayOption(t *testing.T) {
+	require := require.New(t)
+
+	cols, args, err := getStructValues(arrayTest{
+		Tags:   []string{"go", "sql"},
+		Scores: []int64{1, 2},
+		Ratios: []float64{0.5},
+	})
+	require.Nil(err)
+	require.Equal([]string{"tags", "scores", "ratios"}, cols)
+	require.Equal([]interface{}{`{"go","sql"}`, "{1,2}", "{0.5}"}, args)
+
+	values, err := getColumnValues(arrayTest{}, []string{"tags"})
+	require.Nil(err)
+	require.Equal([]interface{}{nil}, values)
+
+	record := arrayTest{Tags: []string{"stale"}}
+	err = scanStruct(&record, map[string]interface{}{
+		"tags":   `{go,"a b"}`,
+		"scores": []byte("{1,2}"),
+		"ratios": "{0.5,NULL}",
+	})
+	require.NotNil(err)
+
+	err = scanStruct(&record, map[string]interface{}{
+		"tags":   `{go,"a b"}`,
+		"scores": []byte("{1,2}"),
+		"ratios": "{0.5,1}",
+	})
+	require.Nil(err)
+	require.Equal(arrayTest{
+		Tags:   []string{"go", "a b"},
+		Scores: []int64{1, 2},
+		Ratios: []float64{0.5, 1},
+	}, record)
+
+	require.Nil(scanStruct(&record, map[string]interface{}{"tags": nil}))
+	require.Nil(record.Tags)
+
+	// Without the array option the value can't be set on the slice
+	require.NotNil(scanStruct(&record, map[string]interface{}{"names": "{go}"}))
+}
+
+func Benchmark_ScanStruct(b *testing.B) {
+	timestamp := time.Now()
+	vals := map[string]interface{}{
+		"id":         int64(1),
+		"name":       "gerald",
+		"total":      12.00,
+		"created_at": timestamp,
+		"is_active":  true,
+		"row_count":  int64(2),
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		record := scanTest{}
+		if err := scanStruct(&record, vals); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_ScanStructSlice(b *testing.B) {
+	timestamp := time.Now()
+	vals := map[string]interface{}{
+		"id":         int64(1),
+		"name":       "gerald",
+		"total":      12.00,
+		"created_at": timestamp,
+		"is_active":  true,
+		"row_count":  int64(2),
+	}
+
+	b.ReportAllocs()
+	records := make([]scanTest, 0, b.N)
+	for i := 0; i < b.N; i++ {
+		if err := scanStructSlice(&records, vals); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_GetStructValues(b *testing.B) {
+	record := scanTest{
+		ID:        1,
+		Name:      "gerald",
+		Total:     12.00,
+		CreatedAt: time.Now(),
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := getStructValues(record); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
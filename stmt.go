@@ -0,0 +1,289 @@
+package fluent
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+
+	"github.com/lib/pq"
+)
+
+// stalePlanMessage is returned by Postgres when the result type
+// of a prepared statement changed, for example after a migration
+const stalePlanMessage = "cached plan must not change result type"
+
+// StmtCacheStats are the statistics of the statement cache
+type StmtCacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// Evictions counts the statements removed because the cache was full
+	// or because the plan of the statement was no longer valid
+	Evictions uint64
+	Size      int
+}
+
+// stmtCache is a least recently used cache of prepared statements keyed by
+// the query, it's shared between the clones of the Fluent struct
+type stmtCache struct {
+	mutex   sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+	stats   StmtCacheStats
+}
+
+// cachedStmt is a statement of the cache, it's closed when
+// it has been evicted and isn't in use anymore
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(size int) *stmtCache {
+	return &stmtCache{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element, size),
+	}
+}
+
+// get returns the cached statement of the query or prepares it,
+// the statement has to be released when done
+func (c *stmtCache) get(ctx context.Context, db *sql.DB, query string) (*cachedStmt, error) {
+	if entry := c.acquire(query); entry != nil {
+		return entry, nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	// Another goroutine could have prepared the same query in the meantime
+	if el, ok := c.entries[query]; ok {
+		entry := el.Value.(*cachedStmt)
+		entry.refs++
+		c.mutex.Unlock()
+
+		stmt.Close()
+		return entry, nil
+	}
+
+	entry := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.entries[query] = c.order.PushFront(entry)
+
+	var evicted []*cachedStmt
+	for c.order.Len() > c.size {
+		if e := c.remove(c.order.Back()); e != nil {
+			evicted = append(evicted, e)
+		}
+	}
+	c.mutex.Unlock()
+
+	for _, e := range evicted {
+		e.stmt.Close()
+	}
+
+	return entry, nil
+}
+
+// acquire returns the cached statement of the query and marks it as used
+func (c *stmtCache) acquire(query string) *cachedStmt {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	el, ok := c.entries[query]
+	if !ok {
+		c.stats.Misses++
+		return nil
+	}
+
+	c.stats.Hits++
+	c.order.MoveToFront(el)
+
+	entry := el.Value.(*cachedStmt)
+	entry.refs++
+	return entry
+}
+
+// release marks the statement as unused, it's closed when it has been
+// evicted. An invalid statement is evicted so it will be prepared again
+func (c *stmtCache) release(entry *cachedStmt, invalid bool) error {
+	c.mutex.Lock()
+	if invalid {
+		if el, ok := c.entries[entry.query]; ok && el.Value == entry {
+			c.remove(el)
+		}
+	}
+
+	entry.refs--
+	closeStmt := entry.evicted && entry.refs == 0
+	c.mutex.Unlock()
+
+	if closeStmt {
+		return entry.stmt.Close()
+	}
+	return nil
+}
+
+// remove evicts the element from the cache, it returns the
+// statement when it can be closed right away
+func (c *stmtCache) remove(el *list.Element) *cachedStmt {
+	entry := c.order.Remove(el).(*cachedStmt)
+	delete(c.entries, entry.query)
+	entry.evicted = true
+	c.stats.Evictions++
+
+	if entry.refs == 0 {
+		return entry
+	}
+	return nil
+}
+
+// purge evicts all the statements
+func (c *stmtCache) purge() {
+	c.mutex.Lock()
+	var evicted []*cachedStmt
+	for c.order.Len() > 0 {
+		if e := c.remove(c.order.Back()); e != nil {
+			evicted = append(evicted, e)
+		}
+	}
+	c.mutex.Unlock()
+
+	for _, e := range evicted {
+		e.stmt.Close()
+	}
+}
+
+func (c *stmtCache) getStats() StmtCacheStats {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stats := c.stats
+	stats.Size = c.order.Len()
+	return stats
+}
+
+// statement is a prepared statement of the query, closing
+// it releases the statement when it's cached
+type statement struct {
+	*sql.Stmt
+	close func(invalid bool) error
+}
+
+// Close the statement
+func (s *statement) Close() error {
+	return s.close(false)
+}
+
+// StmtCache enables a cache of the given number of prepared statements,
+// the statements are reused by queries with the same SQL. A size of 0
+// disables the cache, it should be set before the mapper is shared
+func (f *Fluent) StmtCache(size int) Mapper {
+	if f.stmts != nil {
+		f.stmts.purge()
+		f.stmts = nil
+	}
+
+	if size > 0 {
+		f.stmts = newStmtCache(size)
+	}
+	return f
+}
+
+// StmtCacheStats returns the statistics of the statement cache
+func (f *Fluent) StmtCacheStats() StmtCacheStats {
+	if f.stmts == nil {
+		return StmtCacheStats{}
+	}
+	return f.stmts.getStats()
+}
+
+// statement prepares the query and calls fn with the prepared statement.
+// When the plan of a cached statement is no longer valid the statement is
+// prepared again and fn is retried once, within a transaction the error
+// is returned since the transaction is aborted. The statement has to be
+// closed when no error is returned
+func (f *Fluent) statement(fn func(stmt *sql.Stmt) error) (*statement, error) {
+	for attempt := 1; ; attempt++ {
+		stmt, err := f.prepare()
+		if err != nil {
+			return nil, err
+		}
+
+		err = fn(stmt.Stmt)
+		if err == nil {
+			return stmt, nil
+		}
+
+		stale := f.stmts != nil && isStalePlan(err)
+		stmt.close(stale)
+		if !stale || f.tx != nil || attempt > 1 {
+			return nil, err
+		}
+	}
+}
+
+// prepare returns the cached statement of the query when the cache
+// is enabled, otherwise it prepares a new statement
+func (f *Fluent) prepare() (*statement, error) {
+	if f.stmts == nil {
+		return f.prepareUncached()
+	}
+
+	cache := f.stmts
+	if f.tx == nil {
+		entry, err := cache.get(f.ctx, f.db, f.query.stmt)
+		if err != nil {
+			return nil, err
+		}
+
+		return &statement{entry.stmt, func(invalid bool) error {
+			return cache.release(entry, invalid)
+		}}, nil
+	}
+
+	// Preparing a missing statement on the database would need a second
+	// connection while the transaction holds its own, so within a
+	// transaction only statements that are already cached are reused
+	entry := cache.acquire(f.query.stmt)
+	if entry == nil {
+		return f.prepareUncached()
+	}
+
+	// The statement of the transaction is closed with the transaction,
+	// the cached statement is released once it's closed
+	stmt := f.tx.StmtContext(f.ctx, entry.stmt)
+	return &statement{stmt, func(invalid bool) error {
+		err := stmt.Close()
+		if releaseErr := cache.release(entry, invalid); err == nil {
+			err = releaseErr
+		}
+		return err
+	}}, nil
+}
+
+// prepareUncached prepares a statement that is closed after use
+func (f *Fluent) prepareUncached() (*statement, error) {
+	stmt, err := f.executor().PrepareContext(f.ctx, f.query.stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &statement{stmt, func(bool) error {
+		return stmt.Close()
+	}}, nil
+}
+
+// isStalePlan checks if the error is caused by a cached plan
+// which result type changed
+func isStalePlan(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Message == stalePlanMessage
+}
@@ -0,0 +1,155 @@
+package fluent
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// stmtDriver counts the prepared and closed statements, the
+// first stale executions return a cached plan error. Queries
+// return rows with the ids 1 to rows followed by rowsErr
+type stmtDriver struct {
+	prepared   int32
+	closed     int32
+	stale      int32
+	rows       int
+	rowsErr    error
+	rowsClosed int32
+}
+
+func (d *stmtDriver) Connect(ctx context.Context) (driver.Conn, error) { return &stmtConn{d}, nil }
+func (d *stmtDriver) Driver() driver.Driver                            { return nil }
+
+type stmtConn struct{ d *stmtDriver }
+
+func (c *stmtConn) Prepare(query string) (driver.Stmt, error) {
+	atomic.AddInt32(&c.d.prepared, 1)
+	return &stmtStmt{c.d}, nil
+}
+func (c *stmtConn) Close() error              { return nil }
+func (c *stmtConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *stmtConn) Commit() error             { return nil }
+func (c *stmtConn) Rollback() error           { return nil }
+
+type stmtStmt struct{ d *stmtDriver }
+
+func (s *stmtStmt) Close() error  { atomic.AddInt32(&s.d.closed, 1); return nil }
+func (s *stmtStmt) NumInput() int { return -1 }
+func (s *stmtStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if atomic.AddInt32(&s.d.stale, -1) >= 0 {
+		return nil, &pq.Error{Code: "0A000", Message: stalePlanMessage}
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s *stmtStmt) Query(args []driver.Value) (driver.Rows, error) { return &stmtRows{d: s.d}, nil }
+
+type stmtRows struct {
+	d    *stmtDriver
+	read int
+}
+
+func (r *stmtRows) Columns() []string { return []string{"id"} }
+func (r *stmtRows) Close() error      { atomic.AddInt32(&r.d.rowsClosed, 1); return nil }
+func (r *stmtRows) Next(dest []driver.Value) error {
+	if r.read == r.d.rows {
+		if r.d.rowsErr != nil {
+			return r.d.rowsErr
+		}
+		return io.EOF
+	}
+
+	r.read++
+	dest[0] = int64(r.read)
+	return nil
+}
+
+func Test_StmtCache(t *testing.T) {
+	require := require.New(t)
+
+	d := &stmtDriver{}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	m := New(db).StmtCache(2)
+	require.Nil(m.Table("test_1").Where("id", "=", 1).Delete())
+	require.Nil(m.Table("test_1").Where("id", "=", 2).Delete())
+	require.Equal(StmtCacheStats{Hits: 1, Misses: 1, Size: 1}, m.StmtCacheStats())
+	require.Equal(int32(1), atomic.LoadInt32(&d.prepared))
+
+	// The cursor keeps the statement in use while it's evicted
+	cursor, err := m.Table("test_1").Get("id").Cursor()
+	require.Nil(err)
+	require.Nil(m.Table("test_2").Where("id", "=", 1).Delete())
+	require.Nil(m.Table("test_3").Where("id", "=", 1).Delete())
+	require.Equal(StmtCacheStats{Hits: 1, Misses: 4, Evictions: 2, Size: 2}, m.StmtCacheStats())
+
+	require.False(cursor.Next())
+	require.Nil(cursor.Err())
+	require.Nil(cursor.Close())
+	require.Equal(int32(2), atomic.LoadInt32(&d.closed))
+
+	// A stale plan is evicted and the statement prepared again
+	atomic.StoreInt32(&d.stale, 1)
+	require.Nil(m.Table("test_3").Where("id", "=", 1).Delete())
+	require.Equal(StmtCacheStats{Hits: 2, Misses: 5, Evictions: 3, Size: 2}, m.StmtCacheStats())
+
+	// Within a transaction the error is returned
+	atomic.StoreInt32(&d.stale, 1)
+	err = m.Transaction(context.Background(), func(tx Mapper) error {
+		return tx.Table("test_3").Where("id", "=", 1).Delete()
+	})
+	require.True(isStalePlan(err))
+
+	err = m.Transaction(context.Background(), func(tx Mapper) error {
+		return tx.Table("test_3").Where("id", "=", 1).Delete()
+	})
+	require.Nil(err)
+
+	// Within a transaction a missing statement is prepared on the transaction,
+	// a single connection is enough to prepare and reuse the statements
+	db.SetMaxOpenConns(1)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	stats := m.StmtCacheStats()
+	err = m.Transaction(ctx, func(tx Mapper) error {
+		if err := tx.Table("test_4").Where("id", "=", 1).Delete(); err != nil {
+			return err
+		}
+		return tx.Table("test_2").Where("id", "=", 1).Delete()
+	})
+	require.Nil(err)
+	require.Equal(StmtCacheStats{
+		Hits:      stats.Hits + 1,
+		Misses:    stats.Misses + 1,
+		Evictions: stats.Evictions,
+		Size:      stats.Size,
+	}, m.StmtCacheStats())
+
+	m.StmtCache(0)
+	require.Equal(StmtCacheStats{}, m.StmtCacheStats())
+	require.Equal(atomic.LoadInt32(&d.prepared), atomic.LoadInt32(&d.closed))
+}
+
+func Test_WithoutStmtCache(t *testing.T) {
+	require := require.New(t)
+
+	d := &stmtDriver{}
+	db := sql.OpenDB(d)
+	defer db.Close()
+
+	m := New(db)
+	require.Nil(m.Table("test_1").Where("id", "=", 1).Delete())
+	require.Nil(m.Table("test_1").Where("id", "=", 2).Delete())
+	require.Equal(StmtCacheStats{}, m.StmtCacheStats())
+	require.Equal(int32(2), atomic.LoadInt32(&d.prepared))
+	require.Equal(int32(2), atomic.LoadInt32(&d.closed))
+}
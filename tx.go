@@ -0,0 +1,125 @@
+package fluent
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+const (
+	savepointStatement         = "SAVEPOINT %s"
+	releaseSavepointStatement  = "RELEASE SAVEPOINT %s"
+	rollbackSavepointStatement = "ROLLBACK TO SAVEPOINT %s"
+)
+
+// savepointCounter is used to generate unique savepoint names
+var savepointCounter uint64
+
+// TxMapper exposes the functionalities of the Mapper
+// within a transaction that has to be committed or rolled back
+type TxMapper interface {
+	Mapper
+	Commit() error
+	Rollback() error
+}
+
+// Begin starts a transaction
+func (f *Fluent) Begin() (TxMapper, error) {
+	return f.BeginTx(f.ctx, nil)
+}
+
+// BeginTx starts a transaction with the given context and options,
+// the context is used for the queries within the transaction
+func (f *Fluent) BeginTx(ctx context.Context, opts *sql.TxOptions) (TxMapper, error) {
+	if f.tx != nil {
+		return nil, ErrTxStarted
+	}
+
+	tx, err := f.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	m := f.clone()
+	m.tx, m.ctx = tx, ctx
+	return m, nil
+}
+
+// Transaction runs the function within a transaction, the transaction
+// is rolled back when the function returns an error or panics
+// and committed otherwise. Within a transaction it runs the
+// function in a savepoint so only its own work is rolled back
+func (f *Fluent) Transaction(ctx context.Context, fn func(Mapper) error) error {
+	if f.tx != nil {
+		return f.savepoint(ctx, fn)
+	}
+
+	return f.runTransaction(ctx, nil, fn)
+}
+
+// runTransaction starts a transaction with the given options and runs the function
+func (f *Fluent) runTransaction(ctx context.Context, opts *sql.TxOptions, fn func(Mapper) error) error {
+	tx, err := f.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return runTx(tx, tx.Commit, tx.Rollback, fn)
+}
+
+// savepoint runs the function within a savepoint of the
+// current transaction, the savepoint is released on success
+func (f *Fluent) savepoint(ctx context.Context, fn func(Mapper) error) error {
+	name := fmt.Sprintf("fluent_%d", atomic.AddUint64(&savepointCounter, 1))
+	if _, err := f.tx.ExecContext(ctx, fmt.Sprintf(savepointStatement, name)); err != nil {
+		return err
+	}
+
+	release := func() error {
+		_, err := f.tx.ExecContext(ctx, fmt.Sprintf(releaseSavepointStatement, name))
+		return err
+	}
+	rollback := func() error {
+		_, err := f.tx.ExecContext(ctx, fmt.Sprintf(rollbackSavepointStatement, name))
+		return err
+	}
+
+	m := f.clone()
+	m.ctx = ctx
+	return runTx(m, release, rollback, fn)
+}
+
+// runTx runs the function and commits or rolls back
+// depending on the returned error or a panic
+func runTx(m Mapper, commit, rollback func() error, fn func(Mapper) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			rollback()
+			err = fmt.Errorf("Transaction rolled back after panic: %v", p)
+		}
+	}()
+
+	if err := fn(m); err != nil {
+		rollback()
+		return err
+	}
+
+	return commit()
+}
+
+// Commit the transaction
+func (f *Fluent) Commit() error {
+	if f.tx == nil {
+		return ErrNoTx
+	}
+	return wrapError(f.tx.Commit())
+}
+
+// Rollback the transaction
+func (f *Fluent) Rollback() error {
+	if f.tx == nil {
+		return ErrNoTx
+	}
+	return f.tx.Rollback()
+}
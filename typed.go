@@ -0,0 +1,234 @@
+package fluent
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// TypedQuery builds a query on top of the QueryMapper and
+// scans the records into T, which has to be a struct type
+type TypedQuery[T any] struct {
+	query   QueryMapper
+	columns []string
+}
+
+// Query starts a typed query on the table, the selected columns are
+// taken from the sql tags of T. The columns of prefixed structs aren't
+// selected, use Select with aliases to join them, for example:
+//
+//	records, err := fluent.Query[Record](mapper, "test").Where("id", ">", 1).All(ctx)
+func Query[T any](m Mapper, table string) *TypedQuery[T] {
+	return &TypedQuery[T]{
+		query:   m.Table(table),
+		columns: getColumns(reflect.TypeOf((*T)(nil)).Elem()),
+	}
+}
+
+// Select overrides the columns taken from the sql tags of T
+func (q *TypedQuery[T]) Select(columns ...string) *TypedQuery[T] {
+	q.columns = columns
+	return q
+}
+
+// Join set the table and columns for the join query
+func (q *TypedQuery[T]) Join(table, column1, column2 string) *TypedQuery[T] {
+	q.query.Join(table, column1, column2)
+	return q
+}
+
+// LeftJoin set the table and columns for the left join query
+func (q *TypedQuery[T]) LeftJoin(table, column1, column2 string) *TypedQuery[T] {
+	q.query.LeftJoin(table, column1, column2)
+	return q
+}
+
+// Where set the column, operator and the value for the where clause
+func (q *TypedQuery[T]) Where(column, operator string, value interface{}) *TypedQuery[T] {
+	q.query.Where(column, operator, value)
+	return q
+}
+
+// OrWhere set the column, operator and the value for
+// the where clause joined by OR
+func (q *TypedQuery[T]) OrWhere(column, operator string, value interface{}) *TypedQuery[T] {
+	q.query.OrWhere(column, operator, value)
+	return q
+}
+
+// WhereNull set if the column is null or not null
+func (q *TypedQuery[T]) WhereNull(column string, isNull bool) *TypedQuery[T] {
+	q.query.WhereNull(column, isNull)
+	return q
+}
+
+// WhereIn set the column and the values the column should match
+func (q *TypedQuery[T]) WhereIn(column string, values interface{}) *TypedQuery[T] {
+	q.query.WhereIn(column, values)
+	return q
+}
+
+// WhereNotIn set the column and the values the column shouldn't match
+func (q *TypedQuery[T]) WhereNotIn(column string, values interface{}) *TypedQuery[T] {
+	q.query.WhereNotIn(column, values)
+	return q
+}
+
+// WhereBetween set the column and the range the value should be in
+func (q *TypedQuery[T]) WhereBetween(column string, from, to interface{}) *TypedQuery[T] {
+	q.query.WhereBetween(column, from, to)
+	return q
+}
+
+// WhereLike set the column and the pattern it should match
+func (q *TypedQuery[T]) WhereLike(column, pattern string) *TypedQuery[T] {
+	q.query.WhereLike(column, pattern)
+	return q
+}
+
+// WhereILike set the column and the pattern it should match case insensitive
+func (q *TypedQuery[T]) WhereILike(column, pattern string) *TypedQuery[T] {
+	q.query.WhereILike(column, pattern)
+	return q
+}
+
+// WhereJSON set the key of the JSON column, the operator
+// and the value compared as JSON for the where clause
+func (q *TypedQuery[T]) WhereJSON(column, key, operator string, value interface{}) *TypedQuery[T] {
+	q.query.WhereJSON(column, key, operator, value)
+	return q
+}
+
+// WhereJSONText set the key of the JSON column, the operator
+// and the value compared as text for the where clause
+func (q *TypedQuery[T]) WhereJSONText(column, key, operator string, value interface{}) *TypedQuery[T] {
+	q.query.WhereJSONText(column, key, operator, value)
+	return q
+}
+
+// WhereJSONContains set the JSON column and the value it should contain
+func (q *TypedQuery[T]) WhereJSONContains(column string, value interface{}) *TypedQuery[T] {
+	q.query.WhereJSONContains(column, value)
+	return q
+}
+
+// WhereJSONHasKey set the JSON column and the top level key it should have
+func (q *TypedQuery[T]) WhereJSONHasKey(column, key string) *TypedQuery[T] {
+	q.query.WhereJSONHasKey(column, key)
+	return q
+}
+
+// WhereAny set the array column and the value it should hold
+func (q *TypedQuery[T]) WhereAny(column string, value interface{}) *TypedQuery[T] {
+	q.query.WhereAny(column, value)
+	return q
+}
+
+// WhereContains set the array column and the slice of values it should contain
+func (q *TypedQuery[T]) WhereContains(column string, values interface{}) *TypedQuery[T] {
+	q.query.WhereContains(column, values)
+	return q
+}
+
+// WhereOverlaps set the array column and the slice of
+// values it should have at least one in common with
+func (q *TypedQuery[T]) WhereOverlaps(column string, values interface{}) *TypedQuery[T] {
+	q.query.WhereOverlaps(column, values)
+	return q
+}
+
+// WhereGroup wraps the where clauses set in the group between parentheses
+func (q *TypedQuery[T]) WhereGroup(group func(QueryMapper)) *TypedQuery[T] {
+	q.query.WhereGroup(group)
+	return q
+}
+
+// OrWhereGroup wraps the where clauses set in the group
+// between parentheses joined by OR
+func (q *TypedQuery[T]) OrWhereGroup(group func(QueryMapper)) *TypedQuery[T] {
+	q.query.OrWhereGroup(group)
+	return q
+}
+
+// OrderBy set to columns to order by
+func (q *TypedQuery[T]) OrderBy(columns ...string) *TypedQuery[T] {
+	q.query.OrderBy(columns...)
+	return q
+}
+
+// GroupBy set to columns to group by
+func (q *TypedQuery[T]) GroupBy(columns ...string) *TypedQuery[T] {
+	q.query.GroupBy(columns...)
+	return q
+}
+
+// Limit set the limit of records to return
+func (q *TypedQuery[T]) Limit(limit int) *TypedQuery[T] {
+	q.query.Limit(limit)
+	return q
+}
+
+// Offset set the offset for the records to return
+func (q *TypedQuery[T]) Offset(offset int) *TypedQuery[T] {
+	q.query.Offset(offset)
+	return q
+}
+
+// All fetch all the records
+func (q *TypedQuery[T]) All(ctx context.Context) ([]T, error) {
+	records := []T{}
+	get, err := q.get(ctx)
+	if err != nil {
+		return records, err
+	}
+
+	err = get.All(&records)
+	return records, err
+}
+
+// First fetch the first record, it returns ErrNoRows
+// when there are no records
+func (q *TypedQuery[T]) First(ctx context.Context) (T, error) {
+	var record T
+	get, err := q.Limit(1).get(ctx)
+	if err != nil {
+		return record, err
+	}
+
+	err = get.One(&record)
+	return record, err
+}
+
+// Each fetch the records one by one and calls fn for each record,
+// return ErrStop to stop iterating without an error
+func (q *TypedQuery[T]) Each(ctx context.Context, fn func(T) error) error {
+	var record T
+	get, err := q.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	return get.Each(&record, func() error {
+		return fn(record)
+	})
+}
+
+// get builds the select of the columns, there are no columns
+// when T isn't a struct with tagged fields and Select wasn't called
+func (q *TypedQuery[T]) get(ctx context.Context) (ScanMapper, error) {
+	if len(q.columns) == 0 {
+		return nil, fmt.Errorf("No columns to select for %s", reflect.TypeOf((*T)(nil)).Elem())
+	}
+
+	return q.query.WithContext(ctx).Get(q.columns...), nil
+}
+
+// Count returns the number of records
+func (q *TypedQuery[T]) Count(ctx context.Context) (int64, error) {
+	return q.query.WithContext(ctx).Count()
+}
+
+// Exists checks if there is at least one record
+func (q *TypedQuery[T]) Exists(ctx context.Context) (bool, error) {
+	return q.query.WithContext(ctx).Exists()
+}
@@ -0,0 +1,61 @@
+package fluent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Query(t *testing.T) {
+	require := require.New(t)
+
+	tests := []struct {
+		query        *TypedQuery[scanTest]
+		expectedStmt string
+		expectedArgs []interface{}
+	}{
+		{
+			query:        Query[scanTest](New(nil), "test"),
+			expectedStmt: "SELECT row_count,id,name,total,is_active,created_at FROM test OFFSET $1",
+			expectedArgs: []interface{}{0},
+		},
+		{
+			query: Query[scanTest](New(nil), "test").
+				Select("id", "name").
+				WhereIn("id", []int{1, 2}).
+				OrWhere("name", "=", "gerald").
+				OrderBy("id").
+				Limit(5),
+			expectedStmt: "SELECT id,name FROM test WHERE id IN ($1,$2) OR name = $3 ORDER BY id OFFSET $4 LIMIT $5",
+			expectedArgs: []interface{}{1, 2, "gerald", 0, 5},
+		},
+	}
+
+	for _, tc := range tests {
+		f := tc.query.query.Get(tc.query.columns...).(*Fluent)
+
+		require.Equal(tc.expectedStmt, f.query.stmt)
+		require.Equal(tc.expectedArgs, f.query.args)
+	}
+
+	// The prefixed columns of the joined tables aren't selected
+	q := Query[postTest](New(nil), "posts")
+	f := q.query.Get(q.columns...).(*Fluent)
+	require.Equal("SELECT created_at,deleted_at,id,title FROM posts OFFSET $1", f.query.stmt)
+
+	q = Query[postTest](New(nil), "posts as p").
+		Join("authors as a", "a.id", "p.author_id").
+		Select("p.id", "a.id AS author_id", "a.name AS author_name")
+	f = q.query.Get(q.columns...).(*Fluent)
+	require.Equal("SELECT p.id,a.id AS author_id,a.name AS author_name FROM posts as p INNER JOIN authors as a ON a.id = p.author_id OFFSET $1", f.query.stmt)
+
+	_, err := Query[int](New(nil), "test").All(context.Background())
+	require.NotNil(err)
+
+	_, err = Query[struct{ ID int }](New(nil), "test").First(context.Background())
+	require.NotNil(err)
+
+	err = Query[int](New(nil), "test").Each(context.Background(), func(int) error { return nil })
+	require.NotNil(err)
+}